@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -8,6 +10,8 @@ import (
 	"wbnats/internal/app"
 	"wbnats/internal/config"
 	"wbnats/internal/lib/logger/handlers/slogpretty"
+	"wbnats/internal/lib/logger/sl"
+	"wbnats/internal/lib/pii"
 )
 
 const (
@@ -17,54 +21,75 @@ const (
 )
 
 func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
 
-	cfg := config.MustLoad()
+func run() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	policy := pii.Policy{
+		Name:    pii.Mode(cfg.PII.Name),
+		Phone:   pii.Mode(cfg.PII.Phone),
+		Email:   pii.Mode(cfg.PII.Email),
+		Address: pii.Mode(cfg.PII.Address),
+		Zip:     pii.Mode(cfg.PII.Zip),
+	}
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("invalid pii config: %w", err)
+	}
 
-	log := setupLogger(cfg.Env)
+	log := setupLogger(cfg.Env, policy)
 
-	application := app.New(log, cfg.NatsStreaming.ClusterID, cfg.NatsStreaming.ClientID, cfg.PostgresConfig, cfg.HTTPServer)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		return runMigrate(log, cfg.PostgresConfig, os.Args[2:])
+	}
 
-	go func() {
-		application.NatsStreaming.MustRun()
-		application.HTTPServer.Run()
-	}()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
 
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
+	application, err := app.New(log, cfg.NatsStreaming, cfg.PostgresConfig, cfg.Cache, cfg.HTTPServer, policy, cfg.ShutdownTimeout)
+	if err != nil {
+		log.Error("failed to start application", sl.Err(err))
+		return err
+	}
 
-	<-stop
+	if err := application.Run(ctx); err != nil {
+		log.Error("application stopped with an error", sl.Err(err))
+		return err
+	}
 
-	application.NatsStreaming.Stop()
 	log.Info("Gracefully stopped")
+	return nil
 }
 
-func setupLogger(env string) *slog.Logger {
-	var log *slog.Logger
+// setupLogger masks personal data in every handler according to policy.
+func setupLogger(env string, policy pii.Policy) *slog.Logger {
+	var handler slog.Handler
 
 	switch env {
 	case envLocal:
-		log = setupPrettySlog()
+		handler = setupPrettySlog()
 	case envDev:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
-		)
+		handler = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})
 	case envProd:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
-		)
+		handler = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})
 	}
 
-	return log
+	return slog.New(pii.NewHandler(handler, policy))
 }
 
-func setupPrettySlog() *slog.Logger {
+func setupPrettySlog() slog.Handler {
 	opts := slogpretty.PrettyHandlerOptions{
 		SlogOpts: &slog.HandlerOptions{
 			Level: slog.LevelDebug,
 		},
 	}
 
-	handler := opts.NewPrettyHandler(os.Stdout)
-
-	return slog.New(handler)
+	return opts.NewPrettyHandler(os.Stdout)
 }
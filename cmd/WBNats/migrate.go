@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"wbnats/internal/config"
+	"wbnats/internal/lib/cache"
+	"wbnats/internal/repository/postgres"
+	"wbnats/internal/services/order/models"
+)
+
+// runMigrate handles `WBNats migrate up|down [steps]|version`.
+func runMigrate(log *slog.Logger, dbConfig config.PostgresConfig, args []string) error {
+	const op = "main.runMigrate"
+
+	if len(args) == 0 {
+		return fmt.Errorf("%s: usage: migrate up|down [steps]|version", op)
+	}
+
+	orderCache, err := cache.New[*models.Order](cache.Config{Policy: cache.PolicyNone}, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	storage, err := postgres.New(dbConfig.Host, dbConfig.Port, dbConfig.DBName, dbConfig.User, dbConfig.Pass, orderCache)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer storage.Close()
+
+	migrator, err := storage.Migrator(log)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("%s: invalid number of steps %q", op, args[1])
+			}
+		}
+		err = migrator.Down(ctx, steps)
+	case "version":
+		var version int64
+		version, err = migrator.Version(ctx)
+		if err == nil {
+			log.Info("current schema version", slog.Int64("version", version))
+		}
+	default:
+		return fmt.Errorf("%s: unknown migrate command %q", op, args[0])
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
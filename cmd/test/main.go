@@ -1,25 +1,41 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"github.com/nats-io/stan.go"
 	"os"
 )
 
 func main() {
-	sc, err := stan.Connect("test-cluster", "client5")
+	url := flag.String("url", stan.DefaultNatsURL, "NATS server URL")
+	cluster := flag.String("cluster", "test-cluster", "NATS Streaming cluster ID")
+	client := flag.String("client", "client5", "NATS Streaming client ID")
+	subject := flag.String("subject", "foo", "subject to publish the order to")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Println("Использование: test [-url url] [-cluster id] [-client id] [-subject subject] <file>")
+		os.Exit(2)
+	}
+
+	sc, err := stan.Connect(*cluster, *client, stan.NatsURL(*url))
 	if err != nil {
 		fmt.Println("Ошибка при подключении к серверу nats streaming: ", err)
 		return
 	}
-	bytes, _ := os.ReadFile(os.Args[1])
+	defer sc.Close()
+
+	bytes, err := os.ReadFile(flag.Arg(0))
+	if err != nil {
+		fmt.Println("Err: ", err)
+		return
+	}
 	fmt.Println("Data: ", string(bytes))
-	err = sc.Publish("foo", bytes)
+	err = sc.Publish(*subject, bytes)
 	if err != nil {
 		fmt.Println("Err: ", err)
 		return
 	}
 	fmt.Println("Shipped")
-
-	sc.Close()
 }
@@ -1,18 +1,29 @@
 package HTTPApp
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"log/slog"
+	"net/http"
 	"time"
+	"wbnats/internal/config"
+	healthHTTPHandler "wbnats/internal/controller/http-server/health"
+	authMiddleware "wbnats/internal/controller/http-server/middleware/auth"
+	metricsMiddleware "wbnats/internal/controller/http-server/middleware/metrics"
+	requestIDMiddleware "wbnats/internal/controller/http-server/middleware/requestid"
 	timeoutMiddleware "wbnats/internal/controller/http-server/middleware/timeout"
 	orderHTTPHandler "wbnats/internal/controller/http-server/order"
+	"wbnats/internal/controller/http-server/scope"
+	"wbnats/internal/lib/pii"
 	orderService "wbnats/internal/services/order"
 )
 
 type App struct {
 	log    *slog.Logger
-	engine *gin.Engine
+	server *http.Server
 	port   string
 }
 
@@ -20,27 +31,103 @@ func New(
 	log *slog.Logger,
 	port string,
 	Timeout time.Duration,
-	orderService *orderService.Order) *App {
+	authConfig config.AuthConfig,
+	orderService *orderService.Order,
+	piiPolicy pii.Policy,
+	liveness []healthHTTPHandler.Check,
+	readiness []healthHTTPHandler.Check) (*App, error) {
+	const op = "HTTPApp.New"
+
+	authenticate, err := authentication(log, authConfig)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.Default()
+	r.Use(metricsMiddleware.New())
+	r.Use(requestIDMiddleware.New())
 	r.Use(timeoutMiddleware.New(Timeout))
 
-	r.GET("/orders/:id", orderHTTPHandler.NewOrderHandler(log, orderService))
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	r.GET("/healthz", healthHTTPHandler.NewHandler(liveness))
+	r.GET("/readyz", healthHTTPHandler.NewHandler(readiness))
+
+	v1 := r.Group("/v1", authenticate)
+	readOrders := authMiddleware.Require(scope.Read)
+	v1.GET("/orders", readOrders, orderHTTPHandler.NewOrdersHandler(log, orderService, piiPolicy))
+	v1.GET("/orders/:id", readOrders, orderHTTPHandler.NewOrderHandler(log, orderService, piiPolicy))
+	v1.GET("/orders/:id/history", readOrders, orderHTTPHandler.NewOrderHistoryHandler(log, orderService))
+
+	// Unversioned routes kept for clients of the pre-/v1 API.
+	r.GET("/orders", authenticate, readOrders, orderHTTPHandler.NewLegacyOrdersHandler(log, orderService, piiPolicy))
+	r.GET("/orders/:id", authenticate, readOrders, orderHTTPHandler.NewLegacyOrderHandler(log, orderService, piiPolicy))
 
 	return &App{
-		log:    log,
-		engine: r,
-		port:   port,
+		log: log,
+		server: &http.Server{
+			Addr:    port,
+			Handler: r,
+		},
+		port: port,
+	}, nil
+}
+
+// authentication builds the auth middleware from the API keys and JWT settings.
+// Disabled auth lets every request read masked orders.
+func authentication(log *slog.Logger, cfg config.AuthConfig) (func(c *gin.Context), error) {
+	if !cfg.Enabled {
+		log.Warn("http auth is disabled, order API is open to anonymous reads")
+		return authMiddleware.Anonymous(scope.Read), nil
+	}
+
+	var authenticators []authMiddleware.Authenticator
+
+	if len(cfg.APIKeys) > 0 {
+		apiKeys, err := authMiddleware.NewAPIKeys(cfg.APIKeys)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, apiKeys)
 	}
+
+	if cfg.JWT.Algorithm != "" {
+		jwtAuth, err := authMiddleware.NewJWT(cfg.JWT)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, jwtAuth)
+	}
+
+	if len(authenticators) == 0 {
+		return nil, errors.New("auth is enabled but neither api keys nor jwt are configured")
+	}
+
+	return authMiddleware.New(log, authenticators...), nil
 }
 
 func (a *App) Run() error {
 	const op = "HTTPApp.Run"
 
-	err := a.engine.Run(a.port)
-	if err != nil {
+	a.log.Info("http server started", slog.String("port", a.port))
+
+	err := a.server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// Stop stops accepting connections and waits for active requests until ctx
+// is done.
+func (a *App) Stop(ctx context.Context) error {
+	const op = "HTTPApp.Stop"
+
+	a.log.With(slog.String("op", op)).
+		Info("stopping http server", slog.String("port", a.port))
+
+	if err := a.server.Shutdown(ctx); err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
-	a.log.Info("http server started", slog.String("port", a.port))
 	return nil
 }
@@ -1,41 +1,180 @@
 package app
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"golang.org/x/sync/errgroup"
 	"log/slog"
+	"time"
 	HTTPApp "wbnats/internal/app/HTTPServer"
+	cacheWarmupApp "wbnats/internal/app/cacheWarmup"
 	natsStreamingApp "wbnats/internal/app/natsStreaming"
 	"wbnats/internal/config"
+	healthHTTPHandler "wbnats/internal/controller/http-server/health"
+	"wbnats/internal/lib/cache"
+	"wbnats/internal/lib/logger/sl"
+	"wbnats/internal/lib/metrics"
+	"wbnats/internal/lib/pii"
 	"wbnats/internal/repository/postgres"
+	deadLetterService "wbnats/internal/services/deadLetter"
 	"wbnats/internal/services/order"
+	"wbnats/internal/services/order/models"
 )
 
 type App struct {
-	NatsStreaming *natsStreamingApp.App
-	HTTPServer    *HTTPApp.App
+	log             *slog.Logger
+	storage         *postgres.Storage
+	shutdownTimeout time.Duration
+	NatsStreaming   *natsStreamingApp.App
+	HTTPServer      *HTTPApp.App
+	CacheWarmup     *cacheWarmupApp.App
 }
 
 func New(
 	log *slog.Logger,
-	clusterID string,
-	clientID string,
+	natsConfig config.NatsStreamingConfig,
 	dbConfig config.PostgresConfig,
+	cacheConfig config.CacheConfig,
 	HTTPConfig config.HTTPServer,
-) *App {
-	storage, err := postgres.New(dbConfig.Host, dbConfig.Port, dbConfig.DBName, dbConfig.User, dbConfig.Pass)
+	piiPolicy pii.Policy,
+	shutdownTimeout time.Duration,
+) (*App, error) {
+	const op = "app.New"
+
+	orderCache, err := cache.New[*models.Order](cache.Config{
+		Policy:     cacheConfig.Policy,
+		MaxEntries: cacheConfig.MaxEntries,
+		MaxBytes:   cacheConfig.MaxBytes,
+		TTL:        cacheConfig.TTL,
+	}, (*models.Order).ApproxSize)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	storage, err := postgres.New(dbConfig.Host, dbConfig.Port, dbConfig.DBName, dbConfig.User, dbConfig.Pass, orderCache)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if dbConfig.AutoMigrate {
+		if err := migrate(log, storage); err != nil {
+			storage.Close()
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
 	}
 
-	order := orderService.New(log, storage, storage)
+	metrics.RegisterCache(storage.CacheStats)
+	metrics.RegisterPool(storage.PoolStats)
 
-	nutsApp := natsStreamingApp.New(log, clusterID, clientID, order)
+	order := orderService.New(log, storage, storage, storage, storage, storage, storage)
 
-	httpApp := HTTPApp.New(log, HTTPConfig.Port, HTTPConfig.Timeout, order)
+	deadLetters := deadLetterService.New(log, storage)
 
-	storage.RestoreCache()
+	nutsApp, err := natsStreamingApp.New(log, natsConfig, order, deadLetters)
+	if err != nil {
+		storage.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	warmup := cacheWarmupApp.New(log, storage, cacheConfig.WarmUpWindow, cacheConfig.WarmUpBatchSize)
+
+	// Dependencies recover on their own (NATS reconnects, the pool redials)
+	// and an exhausted NATS reconnect stops the process, so liveness only
+	// reports that the HTTP server answers. Outages show up in readiness.
+	var liveness []healthHTTPHandler.Check
+	readiness := []healthHTTPHandler.Check{
+		{Name: "postgres", Check: storage.Ping},
+		{Name: "nats_connection", Check: nutsApp.CheckConnection},
+		{Name: "nats_subscription", Check: nutsApp.CheckSubscription},
+		{Name: "cache_warmup", Check: warmup.Check},
+	}
+
+	httpApp, err := HTTPApp.New(log, HTTPConfig.Port, HTTPConfig.Timeout, HTTPConfig.Auth, order, piiPolicy, liveness, readiness)
+	if err != nil {
+		_ = nutsApp.Stop(context.Background())
+		storage.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
 
 	return &App{
-		NatsStreaming: nutsApp,
-		HTTPServer:    httpApp,
+		log:             log,
+		storage:         storage,
+		shutdownTimeout: shutdownTimeout,
+		NatsStreaming:   nutsApp,
+		HTTPServer:      httpApp,
+		CacheWarmup:     warmup,
+	}, nil
+}
+
+func migrate(log *slog.Logger, storage *postgres.Storage) error {
+	migrator, err := storage.Migrator(log)
+	if err != nil {
+		return err
+	}
+	return migrator.Up(context.Background())
+}
+
+// Run starts all components and supervises them until ctx is done or one of
+// them fails, then shuts everything down. It returns the first failure.
+func (a *App) Run(ctx context.Context) error {
+	const op = "app.Run"
+
+	a.CacheWarmup.Run()
+
+	g, gctx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		return a.NatsStreaming.Run(gctx)
+	})
+	g.Go(func() error {
+		return a.HTTPServer.Run()
+	})
+	g.Go(func() error {
+		<-gctx.Done()
+
+		stopCtx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+		defer cancel()
+
+		return a.Stop(stopCtx)
+	})
+
+	if err := g.Wait(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// Stop shuts the components down in dependency order: no new messages, let
+// in-flight ones finish, drain HTTP, then release the database. Every step
+// runs even if an earlier one fails or ctx expires.
+func (a *App) Stop(ctx context.Context) error {
+	const op = "app.Stop"
+
+	log := a.log.With(slog.String("op", op))
+
+	var errs []error
+
+	log.Info("stopping message consumption")
+	if err := a.NatsStreaming.Stop(ctx); err != nil {
+		log.Error("failed to stop nats streaming", sl.Err(err))
+		errs = append(errs, err)
+	}
+
+	log.Info("stopping cache warm-up")
+	a.CacheWarmup.Stop()
+
+	log.Info("shutting down http server")
+	if err := a.HTTPServer.Stop(ctx); err != nil {
+		log.Error("failed to shut down http server", sl.Err(err))
+		errs = append(errs, err)
+	}
+
+	log.Info("closing database pool")
+	a.storage.Close()
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s: %w", op, errors.Join(errs...))
 	}
+	return nil
 }
@@ -0,0 +1,145 @@
+package cacheWarmupApp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+	"wbnats/internal/lib/logger/sl"
+)
+
+const (
+	defaultBatchSize = 1000
+	retryBaseDelay   = time.Second
+	retryMaxDelay    = time.Minute
+)
+
+type Warmer interface {
+	WarmUp(ctx context.Context, since time.Time, batchSize int, progress func(loaded int)) (int, error)
+}
+
+type App struct {
+	log       *slog.Logger
+	warmer    Warmer
+	window    time.Duration
+	batchSize int
+	// retryDelay is the wait before the first retry of a failed warm-up.
+	retryDelay time.Duration
+
+	ready  atomic.Bool
+	loaded atomic.Int64
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func New(
+	log *slog.Logger,
+	warmer Warmer,
+	window time.Duration,
+	batchSize int,
+) *App {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	return &App{
+		log:        log,
+		warmer:     warmer,
+		window:     window,
+		batchSize:  batchSize,
+		retryDelay: retryBaseDelay,
+		done:       make(chan struct{}),
+	}
+}
+
+// Run starts the warm-up in the background and returns immediately. Orders
+// missing from the cache are still served by the read-through path meanwhile.
+// A failed warm-up is retried with backoff until it succeeds or Stop is called.
+func (a *App) Run() {
+	const op = "cacheWarmupApp.Run"
+
+	log := a.log.With(slog.String("op", op))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+
+	var since time.Time
+	if a.window > 0 {
+		since = time.Now().Add(-a.window)
+	}
+
+	go func() {
+		defer close(a.done)
+
+		delay := a.retryDelay
+		for attempt := 1; ; attempt++ {
+			start := time.Now()
+			log.Info("cache warm-up started",
+				slog.Time("since", since),
+				slog.Int("batch_size", a.batchSize),
+				slog.Int("attempt", attempt),
+			)
+
+			loaded, err := a.warmer.WarmUp(ctx, since, a.batchSize, func(loaded int) {
+				a.loaded.Store(int64(loaded))
+				log.Debug("cache warm-up progress", slog.Int("loaded", loaded))
+			})
+			if err == nil {
+				a.ready.Store(true)
+				log.Info("cache warm-up finished",
+					slog.Int("loaded", loaded),
+					slog.Duration("took", time.Since(start)),
+				)
+				return
+			}
+			if ctx.Err() != nil {
+				log.Info("cache warm-up stopped", slog.Int("loaded", loaded))
+				return
+			}
+
+			log.Error("cache warm-up failed, orders are loaded on demand until a retry succeeds",
+				slog.Int("loaded", loaded),
+				slog.Duration("retry_in", delay),
+				sl.Err(err),
+			)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			delay = min(delay*2, retryMaxDelay)
+		}
+	}()
+}
+
+// Ready reports whether the warm-up has finished successfully.
+func (a *App) Ready() bool {
+	return a.ready.Load()
+}
+
+func (a *App) Loaded() int64 {
+	return a.loaded.Load()
+}
+
+func (a *App) Check(_ context.Context) error {
+	if !a.Ready() {
+		return fmt.Errorf("warm-up in progress, %d orders loaded", a.Loaded())
+	}
+	return nil
+}
+
+func (a *App) Stop() {
+	const op = "cacheWarmupApp.Stop"
+
+	if a.cancel == nil {
+		return
+	}
+
+	a.log.With(slog.String("op", op)).
+		Info("stopping cache warm-up")
+
+	a.cancel()
+	<-a.done
+}
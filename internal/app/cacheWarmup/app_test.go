@@ -0,0 +1,88 @@
+package cacheWarmupApp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeWarmer fails the first failures calls, then loads orders.
+type fakeWarmer struct {
+	failures int
+	orders   int
+	calls    atomic.Int32
+}
+
+func (f *fakeWarmer) WarmUp(_ context.Context, _ time.Time, _ int, progress func(loaded int)) (int, error) {
+	if int(f.calls.Add(1)) <= f.failures {
+		return 0, errors.New("connection refused")
+	}
+	progress(f.orders)
+	return f.orders, nil
+}
+
+func newTestApp(warmer Warmer) *App {
+	a := New(slog.New(slog.NewTextHandler(io.Discard, nil)), warmer, 0, 0)
+	a.retryDelay = time.Millisecond
+	return a
+}
+
+func TestRunReadyAfterSuccess(t *testing.T) {
+	warmer := &fakeWarmer{orders: 3}
+	a := newTestApp(warmer)
+
+	a.Run()
+	<-a.done
+
+	if !a.Ready() {
+		t.Error("Ready() = false after a successful warm-up")
+	}
+	if err := a.Check(context.Background()); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+	if a.Loaded() != 3 {
+		t.Errorf("Loaded() = %d, want 3", a.Loaded())
+	}
+}
+
+func TestRunRetriesFailures(t *testing.T) {
+	warmer := &fakeWarmer{failures: 2, orders: 1}
+	a := newTestApp(warmer)
+
+	a.Run()
+	<-a.done
+
+	if !a.Ready() {
+		t.Error("Ready() = false after a retry succeeded")
+	}
+	if calls := warmer.calls.Load(); calls != 3 {
+		t.Errorf("WarmUp called %d times, want 3", calls)
+	}
+}
+
+func TestRunNotReadyWhileFailing(t *testing.T) {
+	warmer := &fakeWarmer{failures: 1 << 30}
+	a := newTestApp(warmer)
+	a.retryDelay = time.Hour
+
+	a.Run()
+	for warmer.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if a.Ready() {
+		t.Error("Ready() = true after a failed warm-up")
+	}
+	if err := a.Check(context.Background()); err == nil {
+		t.Error("Check() error = nil after a failed warm-up")
+	}
+
+	a.Stop()
+	if a.Ready() {
+		t.Error("Ready() = true after Stop")
+	}
+}
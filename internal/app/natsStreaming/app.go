@@ -1,68 +1,111 @@
 package natsStreamingApp
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"github.com/nats-io/stan.go"
 	"log/slog"
+	"wbnats/internal/config"
+	deadLetterNatsStreaming "wbnats/internal/controller/nutsServer/deadLetter"
 	orderNatsStreaming "wbnats/internal/controller/nutsServer/order"
+	orderStatusNatsStreaming "wbnats/internal/controller/nutsServer/orderStatus"
+	"wbnats/internal/lib/message"
+	deadLetterService "wbnats/internal/services/deadLetter"
 	orderService "wbnats/internal/services/order"
 )
 
+// Transport delivers messages of the configured subjects to their handlers and
+// publishes dead letters back to the broker.
+type Transport interface {
+	// Run consumes messages and blocks until ctx is done or the transport
+	// fails for good.
+	Run(ctx context.Context) error
+	Publish(subject string, data []byte) error
+	// Stop stops consuming, waits for in-flight handlers until ctx is done and
+	// closes the connection.
+	Stop(ctx context.Context) error
+	CheckConnection(ctx context.Context) error
+	CheckSubscription(ctx context.Context) error
+}
+
 type App struct {
-	log               *slog.Logger
-	natsStreamConnect *stan.Conn
-	orderService      *orderService.Order
-	sub               *stan.Subscription
+	Transport
 }
+
 type Order interface {
 }
 
+type route struct {
+	subject string
+	handler func(message.Message)
+}
+
 func New(
 	log *slog.Logger,
-	clusterID string,
-	clientID string,
+	cfg config.NatsStreamingConfig,
 	orderService *orderService.Order,
-) *App {
-	sc, err := stan.Connect(clusterID, clientID)
+	deadLetters *deadLetterService.DeadLetter,
+) (*App, error) {
+	const op = "natsStreamingApp.New"
+
+	routes, err := buildRoutes(log, cfg, orderService, deadLetters)
 	if err != nil {
-		{
-			panic("nats streaming server connection error")
-		}
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return &App{
-		log:               log,
-		natsStreamConnect: &sc,
-		orderService:      orderService,
+	var transport Transport
+	switch cfg.Transport {
+	case config.TransportSTAN, "":
+		transport, err = newStanTransport(log, cfg, routes)
+	case config.TransportJetStream:
+		transport, err = newJetStreamTransport(log, cfg, routes)
+	default:
+		err = fmt.Errorf("unknown transport %q", cfg.Transport)
 	}
-}
-
-func (a *App) MustRun() {
-	if err := a.Run(); err != nil {
-		panic(err)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-}
 
-// Run runs gRPC server.
-func (a *App) Run() error {
-	const op = "natsStreamingApp.Run"
+	a := &App{Transport: transport}
 
-	sub, err := (*a.natsStreamConnect).Subscribe("foo", orderNatsStreaming.NewOrderSaverHandler(a.log, a.orderService))
-	a.sub = &sub
-	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
-	}
+	deadLetters.AddSink(deadLetterNatsStreaming.NewPublisher(a, cfg.DeadLetterSubject))
 
-	a.log.Info("nats streaming server started")
-	return nil
+	return a, nil
 }
 
-func (a *App) Stop() {
-	const op = "natsStreamingApp.Stop"
+// buildRoutes resolves the handler of every configured subject. Without
+// configured subjects orders are consumed from "foo" as before.
+func buildRoutes(
+	log *slog.Logger,
+	cfg config.NatsStreamingConfig,
+	orderService *orderService.Order,
+	deadLetters *deadLetterService.DeadLetter,
+) ([]route, error) {
+	subjects := cfg.Subjects
+	if len(subjects) == 0 {
+		subjects = []config.Subject{{Name: "foo", Handler: config.HandlerOrders}}
+	}
+
+	routes := make([]route, 0, len(subjects))
+	for _, subject := range subjects {
+		if subject.Name == "" {
+			return nil, errors.New("subject name must not be empty")
+		}
 
-	a.log.With(slog.String("op", op)).
-		Info("stopping nats streaming server")
+		var handler func(message.Message)
+		switch subject.Handler {
+		case config.HandlerOrders:
+			handler = orderNatsStreaming.NewOrderSaverHandler(log, orderService, deadLetters)
+		case config.HandlerOrderUpdate:
+			handler = orderNatsStreaming.NewOrderUpdateHandler(log, orderService, deadLetters)
+		case config.HandlerOrderStatus:
+			handler = orderStatusNatsStreaming.NewStatusChangeHandler(log, orderService, deadLetters)
+		default:
+			return nil, fmt.Errorf("unknown handler %q for subject %q", subject.Handler, subject.Name)
+		}
+
+		routes = append(routes, route{subject: subject.Name, handler: handler})
+	}
 
-	(*a.sub).Unsubscribe()
-	(*a.natsStreamConnect).Close()
+	return routes, nil
 }
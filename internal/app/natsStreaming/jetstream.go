@@ -0,0 +1,327 @@
+package natsStreamingApp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+	"wbnats/internal/config"
+	"wbnats/internal/lib/logger/sl"
+	"wbnats/internal/lib/message"
+)
+
+const jetStreamRequestTimeout = 5 * time.Second
+
+// jetStreamTransport consumes the subjects through durable pull consumers of an
+// existing stream. Reconnects are left to the NATS client; consumers resume on
+// their own once the connection is back.
+type jetStreamTransport struct {
+	log    *slog.Logger
+	cfg    config.NatsStreamingConfig
+	routes []route
+
+	nc     *nats.Conn
+	js     jetstream.JetStream
+	closed chan struct{}
+
+	mu        sync.RWMutex
+	consumers []jetstream.Consumer
+	consumes  []jetstream.ConsumeContext
+	draining  bool
+	inFlight  sync.WaitGroup
+}
+
+func newJetStreamTransport(log *slog.Logger, cfg config.NatsStreamingConfig, routes []route) (*jetStreamTransport, error) {
+	if cfg.JetStream.Stream == "" {
+		return nil, errors.New("jetstream stream must be set")
+	}
+	if cfg.DurableName == "" {
+		return nil, errors.New("durable_name must be set for jetstream")
+	}
+
+	t := &jetStreamTransport{
+		log:    log,
+		cfg:    cfg,
+		routes: routes,
+		closed: make(chan struct{}),
+	}
+
+	opts, err := natsOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	maxReconnects := cfg.ReconnectMaxAttempts
+	if maxReconnects <= 0 {
+		maxReconnects = -1
+	}
+	opts = append(opts,
+		nats.MaxReconnects(maxReconnects),
+		nats.ReconnectWait(cfg.ReconnectWait),
+		nats.PingInterval(cfg.PingInterval),
+		nats.MaxPingsOutstanding(cfg.PingMaxOut),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			log.Warn("nats connection lost", sl.Err(err))
+		}),
+		nats.ReconnectHandler(func(nc *nats.Conn) {
+			log.Info("nats connection restored", slog.String("url", nc.ConnectedUrlRedacted()))
+		}),
+		nats.ClosedHandler(func(*nats.Conn) {
+			close(t.closed)
+		}),
+	)
+
+	nc, err := nats.Connect(strings.Join(cfg.URLs, ","), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("connect to nats: %w", err)
+	}
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		nc.Close()
+		return nil, err
+	}
+	t.nc, t.js = nc, js
+
+	return t, nil
+}
+
+// Run creates or updates the consumers, starts pulling and blocks until ctx is
+// done. It fails when the connection is closed for good.
+func (t *jetStreamTransport) Run(ctx context.Context) error {
+	const op = "natsStreamingApp.jetStream.Run"
+
+	if err := t.subscribe(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-t.closed:
+		t.mu.RLock()
+		draining := t.draining
+		t.mu.RUnlock()
+		if draining {
+			return nil
+		}
+		return fmt.Errorf("%s: nats connection closed", op)
+	}
+}
+
+func (t *jetStreamTransport) subscribe(ctx context.Context) error {
+	deliverPolicy, optStartSeq, optStartTime, err := t.deliverPolicy()
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.draining {
+		return errors.New("app is stopping")
+	}
+
+	for _, r := range t.routes {
+		consumerCfg := jetstream.ConsumerConfig{
+			Durable:       consumerName(t.cfg.DurableName, r.subject),
+			FilterSubject: r.subject,
+			DeliverPolicy: deliverPolicy,
+			OptStartSeq:   optStartSeq,
+			OptStartTime:  optStartTime,
+			AckPolicy:     jetstream.AckExplicitPolicy,
+			AckWait:       t.cfg.AckWait,
+			MaxDeliver:    t.cfg.JetStream.MaxDeliver,
+			BackOff:       t.cfg.JetStream.BackOff,
+			MaxAckPending: t.cfg.MaxInFlight,
+		}
+
+		reqCtx, cancel := context.WithTimeout(ctx, jetStreamRequestTimeout)
+		consumer, err := t.js.CreateOrUpdateConsumer(reqCtx, t.cfg.JetStream.Stream, consumerCfg)
+		cancel()
+		if err != nil {
+			t.stopConsumes()
+			return fmt.Errorf("create consumer %q: %w", consumerCfg.Durable, err)
+		}
+
+		cc, err := consumer.Consume(t.track(r.handler),
+			jetstream.PullMaxMessages(max(t.cfg.MaxInFlight, 1)),
+			jetstream.ConsumeErrHandler(func(_ jetstream.ConsumeContext, err error) {
+				t.log.Warn("jetstream consume error", slog.String("subject", r.subject), sl.Err(err))
+			}),
+		)
+		if err != nil {
+			t.stopConsumes()
+			return fmt.Errorf("consume %q: %w", r.subject, err)
+		}
+		t.consumers = append(t.consumers, consumer)
+		t.consumes = append(t.consumes, cc)
+
+		t.log.Info("subscribed to jetstream subject",
+			slog.String("stream", t.cfg.JetStream.Stream),
+			slog.String("subject", r.subject),
+			slog.String("consumer", consumerCfg.Durable),
+			slog.String("start_position", t.cfg.StartPosition),
+		)
+	}
+
+	t.log.Info("jetstream consumers started")
+	return nil
+}
+
+// stopConsumes stops pulling; buffered messages are dropped and redelivered
+// after ack wait. Callers hold mu.
+func (t *jetStreamTransport) stopConsumes() {
+	for _, cc := range t.consumes {
+		cc.Stop()
+	}
+	t.consumes = nil
+	t.consumers = nil
+}
+
+func (t *jetStreamTransport) deliverPolicy() (jetstream.DeliverPolicy, uint64, *time.Time, error) {
+	switch t.cfg.StartPosition {
+	case config.StartPositionNewOnly:
+		return jetstream.DeliverNewPolicy, 0, nil, nil
+	case config.StartPositionLastReceived:
+		return jetstream.DeliverLastPolicy, 0, nil, nil
+	case config.StartPositionAllAvailable:
+		return jetstream.DeliverAllPolicy, 0, nil, nil
+	case config.StartPositionSequence:
+		return jetstream.DeliverByStartSequencePolicy, t.cfg.StartSequence, nil, nil
+	case config.StartPositionTime:
+		startTime, err := time.Parse(time.RFC3339, t.cfg.StartTime)
+		if err != nil {
+			return 0, 0, nil, fmt.Errorf("invalid start_time %q: %w", t.cfg.StartTime, err)
+		}
+		return jetstream.DeliverByStartTimePolicy, 0, &startTime, nil
+	default:
+		return 0, 0, nil, fmt.Errorf("unknown start_position %q", t.cfg.StartPosition)
+	}
+}
+
+// consumerName derives a durable consumer name per subject; consumer names may
+// not contain the subject separators and wildcards.
+func consumerName(durable, subject string) string {
+	return durable + "_" + strings.NewReplacer(".", "_", "*", "any", ">", "all").Replace(subject)
+}
+
+// track counts running handlers so Stop can wait for them. Messages arriving
+// after Stop began are left unacknowledged and will be redelivered.
+func (t *jetStreamTransport) track(handler func(message.Message)) jetstream.MessageHandler {
+	return func(m jetstream.Msg) {
+		t.mu.RLock()
+		if t.draining {
+			t.mu.RUnlock()
+			return
+		}
+		t.inFlight.Add(1)
+		t.mu.RUnlock()
+
+		defer t.inFlight.Done()
+
+		msg := jetStreamMessage{
+			Msg:           m,
+			receivedAt:    time.Now(),
+			maxDeliveries: max(t.cfg.JetStream.MaxDeliver, 0),
+		}
+		if meta, err := m.Metadata(); err == nil {
+			msg.sequence = meta.Sequence.Stream
+			msg.receivedAt = meta.Timestamp
+			msg.redeliveries = int(meta.NumDelivered) - 1
+		}
+		handler(msg)
+	}
+}
+
+// jetStreamMessage adapts a JetStream message, caching its metadata. The
+// server drops a message once it was delivered MaxDeliver times, so handlers
+// must settle it on the last delivery.
+type jetStreamMessage struct {
+	jetstream.Msg
+	sequence      uint64
+	receivedAt    time.Time
+	redeliveries  int
+	maxDeliveries int
+}
+
+func (j jetStreamMessage) Payload() []byte              { return j.Data() }
+func (j jetStreamMessage) Headers() map[string][]string { return j.Msg.Headers() }
+func (j jetStreamMessage) Sequence() uint64             { return j.sequence }
+func (j jetStreamMessage) ReceivedAt() time.Time        { return j.receivedAt }
+func (j jetStreamMessage) Redeliveries() int            { return j.redeliveries }
+func (j jetStreamMessage) MaxDeliveries() int           { return j.maxDeliveries }
+func (j jetStreamMessage) Nak(delay time.Duration) error {
+	return j.NakWithDelay(delay)
+}
+
+func (t *jetStreamTransport) Publish(subject string, data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), jetStreamRequestTimeout)
+	defer cancel()
+
+	_, err := t.js.Publish(ctx, subject, data)
+	return err
+}
+
+func (t *jetStreamTransport) Stop(ctx context.Context) error {
+	const op = "natsStreamingApp.jetStream.Stop"
+
+	log := t.log.With(slog.String("op", op))
+
+	log.Info("stopping jetstream consumers")
+
+	t.mu.Lock()
+	t.draining = true
+	t.stopConsumes()
+	t.mu.Unlock()
+
+	log.Info("waiting for in-flight messages")
+
+	done := make(chan struct{})
+	go func() {
+		t.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Info("in-flight messages finished")
+	case <-ctx.Done():
+		t.nc.Close()
+		return fmt.Errorf("%s: in-flight messages did not finish: %w", op, ctx.Err())
+	}
+
+	log.Info("closing nats connection")
+	t.nc.Close()
+	return nil
+}
+
+func (t *jetStreamTransport) CheckConnection(_ context.Context) error {
+	if !t.nc.IsConnected() {
+		return fmt.Errorf("connection is %s", t.nc.Status())
+	}
+	return nil
+}
+
+// CheckSubscription asks the server for every consumer, so a consumer deleted
+// behind our back is reported too.
+func (t *jetStreamTransport) CheckSubscription(ctx context.Context) error {
+	t.mu.RLock()
+	consumers := t.consumers
+	t.mu.RUnlock()
+
+	if len(consumers) == 0 {
+		return errors.New("not subscribed")
+	}
+	for _, consumer := range consumers {
+		if _, err := consumer.Info(ctx); err != nil {
+			return fmt.Errorf("consumer %q: %w", consumer.CachedInfo().Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package natsStreamingApp
+
+import (
+	"crypto/tls"
+	"errors"
+	"github.com/nats-io/nats.go"
+	"wbnats/internal/config"
+)
+
+// natsOptions translates the auth and TLS settings into NATS client options.
+func natsOptions(cfg config.NatsStreamingConfig) ([]nats.Option, error) {
+	opts := []nats.Option{
+		nats.Name(cfg.ClientID),
+	}
+
+	auth := cfg.Auth
+	switch {
+	case auth.CredentialsFile != "":
+		opts = append(opts, nats.UserCredentials(auth.CredentialsFile))
+	case auth.NKeySeedFile != "":
+		opt, err := nats.NkeyOptionFromSeed(auth.NKeySeedFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, opt)
+	case auth.Token != "":
+		opts = append(opts, nats.Token(auth.Token))
+	case auth.User != "":
+		opts = append(opts, nats.UserInfo(auth.User, auth.Password))
+	}
+
+	tlsCfg := cfg.TLS
+	if tlsCfg.CAFile != "" {
+		opts = append(opts, nats.RootCAs(tlsCfg.CAFile))
+	}
+	if tlsCfg.CertFile != "" || tlsCfg.KeyFile != "" {
+		if tlsCfg.CertFile == "" || tlsCfg.KeyFile == "" {
+			return nil, errors.New("tls cert_file and key_file must be set together")
+		}
+		opts = append(opts, nats.ClientCert(tlsCfg.CertFile, tlsCfg.KeyFile))
+	}
+	if tlsCfg.InsecureSkipVerify {
+		opts = append(opts, nats.Secure(&tls.Config{InsecureSkipVerify: true}))
+	}
+
+	return opts, nil
+}
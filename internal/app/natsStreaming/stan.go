@@ -0,0 +1,397 @@
+package natsStreamingApp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/stan.go"
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+	"wbnats/internal/config"
+	"wbnats/internal/lib/logger/sl"
+	"wbnats/internal/lib/message"
+)
+
+// stanTransport consumes the subjects from NATS Streaming. It reconnects with
+// backoff on its own since stan connections don't survive a lost server.
+type stanTransport struct {
+	log    *slog.Logger
+	cfg    config.NatsStreamingConfig
+	routes []route
+
+	mu                sync.RWMutex
+	nc                *nats.Conn
+	natsStreamConnect stan.Conn
+	subs              []stan.Subscription
+	reconnecting      bool
+	attempt           int
+	draining          bool
+	inFlight          sync.WaitGroup
+
+	lost chan error
+}
+
+func newStanTransport(log *slog.Logger, cfg config.NatsStreamingConfig, routes []route) (*stanTransport, error) {
+	t := &stanTransport{
+		log:    log,
+		cfg:    cfg,
+		routes: routes,
+		lost:   make(chan error, 1),
+	}
+
+	sc, nc, err := t.connect()
+	if err != nil {
+		return nil, fmt.Errorf("connect to cluster %q: %w", cfg.ClusterID, err)
+	}
+	t.natsStreamConnect, t.nc = sc, nc
+
+	return t, nil
+}
+
+func (t *stanTransport) connect() (stan.Conn, *nats.Conn, error) {
+	opts, err := natsOptions(t.cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nc, err := nats.Connect(strings.Join(t.cfg.URLs, ","), opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pingInterval := max(int(t.cfg.PingInterval/time.Second), 1)
+
+	sc, err := stan.Connect(t.cfg.ClusterID, t.cfg.ClientID,
+		stan.NatsConn(nc),
+		stan.Pings(pingInterval, t.cfg.PingMaxOut),
+		stan.SetConnectionLostHandler(func(_ stan.Conn, reason error) {
+			select {
+			case t.lost <- reason:
+			default:
+			}
+		}),
+	)
+	if err != nil {
+		nc.Close()
+		return nil, nil, err
+	}
+
+	return sc, nc, nil
+}
+
+// closeConn closes the streaming connection and the NATS connection under it,
+// which stan doesn't own when it is passed in with stan.NatsConn.
+func closeConn(sc stan.Conn, nc *nats.Conn) error {
+	err := sc.Close()
+	nc.Close()
+	if err != nil && !errors.Is(err, stan.ErrConnectionClosed) {
+		return err
+	}
+	return nil
+}
+
+// Run subscribes to orders and blocks until ctx is done. When the connection
+// to the streaming server is lost it reconnects with backoff and restores the
+// subscription; it fails only once reconnect attempts are exhausted.
+func (t *stanTransport) Run(ctx context.Context) error {
+	const op = "natsStreamingApp.stan.Run"
+
+	log := t.log.With(slog.String("op", op))
+
+	if err := t.subscribe(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case reason := <-t.lost:
+			log.Warn("nats streaming connection lost", sl.Err(reason))
+
+			if err := t.reconnect(ctx); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return fmt.Errorf("%s: %w", op, err)
+			}
+		}
+	}
+}
+
+func (t *stanTransport) reconnect(ctx context.Context) error {
+	log := t.log.With(slog.String("op", "natsStreamingApp.stan.reconnect"))
+
+	t.mu.Lock()
+	t.reconnecting = true
+	t.attempt = 0
+	oldSC, oldNC := t.natsStreamConnect, t.nc
+	t.subs = nil
+	t.mu.Unlock()
+
+	// The old connection is already dead; closing it releases its resources.
+	_ = closeConn(oldSC, oldNC)
+
+	wait := t.cfg.ReconnectWait
+	if wait <= 0 {
+		wait = time.Second
+	}
+	maxWait := max(t.cfg.ReconnectMaxWait, wait)
+	for attempt := 1; ; attempt++ {
+		if t.cfg.ReconnectMaxAttempts > 0 && attempt > t.cfg.ReconnectMaxAttempts {
+			return fmt.Errorf("giving up after %d reconnect attempts", t.cfg.ReconnectMaxAttempts)
+		}
+
+		t.mu.Lock()
+		t.attempt = attempt
+		t.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(jitter(wait)):
+		}
+
+		sc, nc, err := t.connect()
+		if err == nil {
+			t.mu.Lock()
+			t.natsStreamConnect, t.nc = sc, nc
+			t.mu.Unlock()
+
+			err = t.subscribe()
+			if err == nil {
+				t.mu.Lock()
+				t.reconnecting = false
+				t.mu.Unlock()
+
+				log.Info("nats streaming connection restored", slog.Int("attempt", attempt))
+				return nil
+			}
+			_ = closeConn(sc, nc)
+		}
+
+		log.Warn("nats streaming reconnect failed",
+			slog.Int("attempt", attempt),
+			slog.Duration("next_wait", min(wait*2, maxWait)),
+			sl.Err(err),
+		)
+		wait = min(wait*2, maxWait)
+	}
+}
+
+// jitter spreads reconnects of several instances by up to a fifth of d.
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return d + rand.N(d/5+1)
+}
+
+func (t *stanTransport) subscribe() error {
+	opts, err := t.subscriptionOptions()
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.draining {
+		return errors.New("app is stopping")
+	}
+
+	subs := make([]stan.Subscription, 0, len(t.routes))
+	for _, r := range t.routes {
+		var sub stan.Subscription
+		if t.cfg.QueueGroup != "" {
+			sub, err = t.natsStreamConnect.QueueSubscribe(r.subject, t.cfg.QueueGroup, t.track(r.handler), opts...)
+		} else {
+			sub, err = t.natsStreamConnect.Subscribe(r.subject, t.track(r.handler), opts...)
+		}
+		if err != nil {
+			for _, sub := range subs {
+				_ = sub.Close()
+			}
+			return fmt.Errorf("subscribe to %q: %w", r.subject, err)
+		}
+		subs = append(subs, sub)
+
+		t.log.Info("subscribed to nats streaming subject",
+			slog.String("subject", r.subject),
+			slog.String("durable_name", t.cfg.DurableName),
+			slog.String("queue_group", t.cfg.QueueGroup),
+			slog.String("start_position", t.cfg.StartPosition),
+		)
+	}
+	t.subs = subs
+
+	t.log.Info("nats streaming server started")
+	return nil
+}
+
+// Publish sends data over the current connection, so publishers keep working
+// across reconnects.
+func (t *stanTransport) Publish(subject string, data []byte) error {
+	t.mu.RLock()
+	sc := t.natsStreamConnect
+	reconnecting := t.reconnecting
+	t.mu.RUnlock()
+
+	if reconnecting {
+		return errors.New("nats streaming is reconnecting")
+	}
+	return sc.Publish(subject, data)
+}
+
+func (t *stanTransport) subscriptionOptions() ([]stan.SubscriptionOption, error) {
+	opts := []stan.SubscriptionOption{
+		stan.SetManualAckMode(),
+		stan.AckWait(t.cfg.AckWait),
+		stan.MaxInflight(t.cfg.MaxInFlight),
+	}
+
+	if t.cfg.DurableName != "" {
+		opts = append(opts, stan.DurableName(t.cfg.DurableName))
+	}
+
+	switch t.cfg.StartPosition {
+	case config.StartPositionNewOnly:
+	case config.StartPositionLastReceived:
+		opts = append(opts, stan.StartWithLastReceived())
+	case config.StartPositionAllAvailable:
+		opts = append(opts, stan.DeliverAllAvailable())
+	case config.StartPositionSequence:
+		opts = append(opts, stan.StartAtSequence(t.cfg.StartSequence))
+	case config.StartPositionTime:
+		startTime, err := time.Parse(time.RFC3339, t.cfg.StartTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start_time %q: %w", t.cfg.StartTime, err)
+		}
+		opts = append(opts, stan.StartAtTime(startTime))
+	default:
+		return nil, fmt.Errorf("unknown start_position %q", t.cfg.StartPosition)
+	}
+
+	return opts, nil
+}
+
+// track counts running handlers so Stop can wait for them. Messages arriving
+// after Stop began are left unacknowledged and will be redelivered.
+func (t *stanTransport) track(handler func(message.Message)) stan.MsgHandler {
+	return func(m *stan.Msg) {
+		t.mu.RLock()
+		if t.draining {
+			t.mu.RUnlock()
+			return
+		}
+		t.inFlight.Add(1)
+		t.mu.RUnlock()
+
+		defer t.inFlight.Done()
+		handler(stanMessage{m})
+	}
+}
+
+// stanMessage adapts a NATS Streaming message. STAN has no headers and no
+// negative acknowledgements: a nakked message is redelivered after ack wait,
+// for as long as it stays unacknowledged.
+type stanMessage struct {
+	m *stan.Msg
+}
+
+func (s stanMessage) Subject() string              { return s.m.Subject }
+func (s stanMessage) Payload() []byte              { return s.m.Data }
+func (s stanMessage) Headers() map[string][]string { return nil }
+func (s stanMessage) Sequence() uint64             { return s.m.Sequence }
+func (s stanMessage) ReceivedAt() time.Time        { return time.Unix(0, s.m.Timestamp) }
+func (s stanMessage) Redeliveries() int            { return int(s.m.RedeliveryCount) }
+func (s stanMessage) MaxDeliveries() int           { return 0 }
+func (s stanMessage) Ack() error                   { return s.m.Ack() }
+func (s stanMessage) Nak(time.Duration) error      { return nil }
+
+// Stop closes the subscription, waits for in-flight handlers until ctx is
+// done and closes the connection.
+func (t *stanTransport) Stop(ctx context.Context) error {
+	const op = "natsStreamingApp.stan.Stop"
+
+	log := t.log.With(slog.String("op", op))
+
+	log.Info("closing nats streaming subscription")
+
+	t.mu.Lock()
+	t.draining = true
+	// Close keeps the durable subscription state on the server so the next
+	// start resumes from the last acknowledged message; Unsubscribe drops it.
+	for _, sub := range t.subs {
+		var err error
+		if t.cfg.DurableName != "" {
+			err = sub.Close()
+		} else {
+			err = sub.Unsubscribe()
+		}
+		if err != nil {
+			log.Error("failed to close subscription", sl.Err(err))
+		}
+	}
+	sc, nc := t.natsStreamConnect, t.nc
+	t.mu.Unlock()
+
+	log.Info("waiting for in-flight messages")
+
+	done := make(chan struct{})
+	go func() {
+		t.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Info("in-flight messages finished")
+	case <-ctx.Done():
+		_ = closeConn(sc, nc)
+		return fmt.Errorf("%s: in-flight messages did not finish: %w", op, ctx.Err())
+	}
+
+	log.Info("closing nats streaming connection")
+	if err := closeConn(sc, nc); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (t *stanTransport) CheckConnection(_ context.Context) error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.reconnecting {
+		return fmt.Errorf("disconnected, reconnect attempt %d", t.attempt)
+	}
+
+	nc := t.natsStreamConnect.NatsConn()
+	if nc == nil {
+		return errors.New("connection is closed")
+	}
+	if !nc.IsConnected() {
+		return fmt.Errorf("connection is %s", nc.Status())
+	}
+	return nil
+}
+
+func (t *stanTransport) CheckSubscription(_ context.Context) error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if len(t.subs) == 0 {
+		return errors.New("not subscribed")
+	}
+	for i, sub := range t.subs {
+		if !sub.IsValid() {
+			return fmt.Errorf("subscription to %q is closed", t.routes[i].subject)
+		}
+	}
+	return nil
+}
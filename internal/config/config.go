@@ -1,53 +1,195 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"github.com/ilyakaznacheev/cleanenv"
 	"os"
 	"time"
 )
 
 type Config struct {
-	Env            string              `yaml:"env" env-default:"local"`
-	NatsStreaming  NatsStreamingConfig `yaml:"nats_streaming"`
-	HTTPServer     `yaml:"http_server"`
-	PostgresConfig `yaml:"postgresql"`
+	Env             string              `yaml:"env" env-default:"local"`
+	ShutdownTimeout time.Duration       `yaml:"shutdown_timeout" env-default:"15s"`
+	NatsStreaming   NatsStreamingConfig `yaml:"nats_streaming"`
+	HTTPServer      `yaml:"http_server"`
+	PostgresConfig  `yaml:"postgresql"`
+	Cache           CacheConfig `yaml:"cache"`
+	PII             PIIConfig   `yaml:"pii"`
 }
 
 type NatsStreamingConfig struct {
-	ClusterID string `yaml:"cluster_id"`
-	ClientID  string `yaml:"client_id"`
+	Transport         string        `yaml:"transport" env-default:"stan"`
+	ClusterID         string        `yaml:"cluster_id"`
+	ClientID          string        `yaml:"client_id"`
+	URLs              []string      `yaml:"urls" env-default:"nats://127.0.0.1:4222"`
+	Subjects          []Subject     `yaml:"subjects"`
+	Auth              NatsAuth      `yaml:"auth"`
+	TLS               NatsTLS       `yaml:"tls"`
+	DeadLetterSubject string        `yaml:"dead_letter_subject"`
+	AckWait           time.Duration `yaml:"ack_wait" env-default:"30s"`
+	MaxInFlight       int           `yaml:"max_in_flight" env-default:"16"`
+	DurableName       string        `yaml:"durable_name"`
+	QueueGroup        string        `yaml:"queue_group"`
+	StartPosition     string        `yaml:"start_position" env-default:"new_only"`
+	StartSequence     uint64        `yaml:"start_sequence"`
+	StartTime         string        `yaml:"start_time"`
+
+	PingInterval         time.Duration `yaml:"ping_interval" env-default:"5s"`
+	PingMaxOut           int           `yaml:"ping_max_out" env-default:"3"`
+	ReconnectWait        time.Duration `yaml:"reconnect_wait" env-default:"1s"`
+	ReconnectMaxWait     time.Duration `yaml:"reconnect_max_wait" env-default:"30s"`
+	ReconnectMaxAttempts int           `yaml:"reconnect_max_attempts" env-default:"0"`
+
+	JetStream JetStreamConfig `yaml:"jetstream"`
+}
+
+const (
+	TransportSTAN      = "stan"
+	TransportJetStream = "jetstream"
+)
+
+// JetStreamConfig applies when transport is jetstream. The stream must already
+// exist; a durable pull consumer is created per subject. Handlers dead-letter
+// a message still failing on its MaxDeliver-th delivery; -1 retries forever.
+type JetStreamConfig struct {
+	Stream     string          `yaml:"stream"`
+	MaxDeliver int             `yaml:"max_deliver" env-default:"5"`
+	BackOff    []time.Duration `yaml:"backoff"`
+}
+
+// Subject routes messages of a NATS subject to one of the known handlers.
+type Subject struct {
+	Name    string `yaml:"name"`
+	Handler string `yaml:"handler"`
+}
+
+const (
+	HandlerOrders      = "orders"
+	HandlerOrderStatus = "order_status"
+	HandlerOrderUpdate = "order_update"
+)
+
+type NatsAuth struct {
+	User            string `yaml:"user"`
+	Password        string `yaml:"password"`
+	Token           string `yaml:"token"`
+	CredentialsFile string `yaml:"credentials_file"`
+	NKeySeedFile    string `yaml:"nkey_seed_file"`
+}
+
+type NatsTLS struct {
+	CAFile             string `yaml:"ca_file"`
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+const (
+	StartPositionNewOnly      = "new_only"
+	StartPositionLastReceived = "last_received"
+	StartPositionAllAvailable = "all_available"
+	StartPositionSequence     = "sequence"
+	StartPositionTime         = "time"
+)
+
+// PIIConfig sets the masking mode of each personal delivery field in HTTP
+// responses and logs: none, partial or full.
+type PIIConfig struct {
+	Name    string `yaml:"name" env-default:"partial"`
+	Phone   string `yaml:"phone" env-default:"partial"`
+	Email   string `yaml:"email" env-default:"partial"`
+	Address string `yaml:"address" env-default:"full"`
+	Zip     string `yaml:"zip" env-default:"none"`
 }
 
 type HTTPServer struct {
 	Port    string        `yaml:"port" env-default:":8080"`
 	Timeout time.Duration `yaml:"timeout" env-default:"4s"`
+	Auth    AuthConfig    `yaml:"auth"`
+}
+
+// AuthConfig protects the order API. With auth disabled every request is
+// anonymous and may only read masked orders.
+type AuthConfig struct {
+	Enabled bool      `yaml:"enabled" env-default:"true"`
+	APIKeys []APIKey  `yaml:"api_keys"`
+	JWT     JWTConfig `yaml:"jwt"`
+}
+
+// APIKey grants scopes to requests sending the key in the X-API-Key header.
+// The key is read from KeyFile so it never sits in the config.
+type APIKey struct {
+	Name    string   `yaml:"name"`
+	KeyFile string   `yaml:"key_file"`
+	Scopes  []string `yaml:"scopes"`
+}
+
+// JWTConfig accepts bearer tokens signed with HS256 (SecretFile) or RS256
+// (PublicKeyFile). An empty algorithm disables JWT authentication.
+type JWTConfig struct {
+	Algorithm     string `yaml:"algorithm"`
+	SecretFile    string `yaml:"secret_file"`
+	PublicKeyFile string `yaml:"public_key_file"`
+	Issuer        string `yaml:"issuer"`
+	Audience      string `yaml:"audience"`
 }
 
 type PostgresConfig struct {
-	Host   string `yaml:"host"`
-	Port   string `yaml:"port"`
-	DBName string `yaml:"database_name"`
-	User   string `yaml:"username"`
-	Pass   string `yaml:"password"`
+	Host        string `yaml:"host"`
+	Port        string `yaml:"port"`
+	DBName      string `yaml:"database_name"`
+	User        string `yaml:"username"`
+	Pass        string `yaml:"password"`
+	AutoMigrate bool   `yaml:"auto_migrate" env-default:"true"`
 }
 
-func MustLoad() *Config {
-	configPath := "config/local.yaml"
+type CacheConfig struct {
+	Policy     string        `yaml:"policy" env-default:"lru"`
+	MaxEntries int           `yaml:"max_entries" env-default:"100000"`
+	MaxBytes   int64         `yaml:"max_bytes" env-default:"268435456"`
+	TTL        time.Duration `yaml:"ttl" env-default:"0s"`
 
-	return MustLoadPath(configPath)
+	WarmUpWindow    time.Duration `yaml:"warm_up_window" env-default:"0s"`
+	WarmUpBatchSize int           `yaml:"warm_up_batch_size" env-default:"1000"`
 }
 
-func MustLoadPath(configPath string) *Config {
+const defaultPath = "config/local.yaml"
+
+func Load() (*Config, error) {
+	return LoadPath(defaultPath)
+}
+
+func LoadPath(configPath string) (*Config, error) {
 	// check if file exists
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		panic("config file does not exist: " + configPath)
+		return nil, errors.New("config file does not exist: " + configPath)
 	}
 
 	var cfg Config
 
 	if err := cleanenv.ReadConfig(configPath, &cfg); err != nil {
-		panic("cannot read config: " + err.Error())
+		return nil, fmt.Errorf("cannot read config: %w", err)
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
 	}
 
-	return &cfg
+	return &cfg, nil
+}
+
+// validate rejects values that pass parsing but would misbehave at run time.
+func (c *Config) validate() error {
+	ns := &c.NatsStreaming
+	if ns.DeadLetterSubject == "" {
+		return errors.New("nats_streaming.dead_letter_subject must be set")
+	}
+	if ns.ReconnectWait <= 0 {
+		return errors.New("nats_streaming.reconnect_wait must be positive")
+	}
+	if ns.ReconnectMaxWait < ns.ReconnectWait {
+		return errors.New("nats_streaming.reconnect_max_wait must not be less than reconnect_wait")
+	}
+	return nil
 }
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, yaml string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPathLocal(t *testing.T) {
+	if _, err := LoadPath("../../config/local.yaml"); err != nil {
+		t.Fatalf("LoadPath(local.yaml) error = %v", err)
+	}
+}
+
+func TestLoadPathValidates(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name: "defaults",
+			yaml: "nats_streaming:\n  dead_letter_subject: orders.dead_letter\n",
+		},
+		{
+			name:    "no dead-letter subject",
+			yaml:    "env: test\n",
+			wantErr: "dead_letter_subject must be set",
+		},
+		{
+			name:    "negative reconnect wait",
+			yaml:    "nats_streaming:\n  dead_letter_subject: orders.dead_letter\n  reconnect_wait: -1s\n",
+			wantErr: "reconnect_wait must be positive",
+		},
+		{
+			name:    "negative reconnect max wait",
+			yaml:    "nats_streaming:\n  dead_letter_subject: orders.dead_letter\n  reconnect_max_wait: -1s\n",
+			wantErr: "reconnect_max_wait must not be less than reconnect_wait",
+		},
+		{
+			name:    "max wait below wait",
+			yaml:    "nats_streaming:\n  dead_letter_subject: orders.dead_letter\n  reconnect_wait: 10s\n  reconnect_max_wait: 5s\n",
+			wantErr: "reconnect_max_wait must not be less than reconnect_wait",
+		},
+		{
+			name: "constant wait",
+			yaml: "nats_streaming:\n  dead_letter_subject: orders.dead_letter\n  reconnect_wait: 5s\n  reconnect_max_wait: 5s\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadPath(writeConfig(t, tt.yaml))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("LoadPath() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadPath() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadPathMissingFile(t *testing.T) {
+	if _, err := LoadPath(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("LoadPath() error = nil for a missing file")
+	}
+}
@@ -0,0 +1,69 @@
+package healthHTTPHandler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sync"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+type Check struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type componentStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type response struct {
+	Status     string                     `json:"status"`
+	Components map[string]componentStatus `json:"components"`
+}
+
+// NewHandler runs all checks concurrently and answers 200 when every one
+// passes, 503 otherwise, with the per-component result in the body.
+func NewHandler(checks []Check) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		resp := response{
+			Status:     StatusUp,
+			Components: make(map[string]componentStatus, len(checks)),
+		}
+
+		var (
+			mu sync.Mutex
+			wg sync.WaitGroup
+		)
+		for _, check := range checks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				status := componentStatus{Status: StatusUp}
+				if err := check.Check(c.Request.Context()); err != nil {
+					status = componentStatus{Status: StatusDown, Error: err.Error()}
+				}
+
+				mu.Lock()
+				resp.Components[check.Name] = status
+				if status.Status == StatusDown {
+					resp.Status = StatusDown
+				}
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+
+		code := http.StatusOK
+		if resp.Status == StatusDown {
+			code = http.StatusServiceUnavailable
+		}
+		c.JSON(code, resp)
+	}
+}
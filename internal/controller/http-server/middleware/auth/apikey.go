@@ -0,0 +1,58 @@
+package authMiddleware
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"wbnats/internal/config"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// APIKeys authenticates requests by a static key in the X-API-Key header.
+// Keys are kept as SHA-256 digests so lookups don't leak them through timing.
+type APIKeys struct {
+	keys map[[sha256.Size]byte]Principal
+}
+
+func NewAPIKeys(keys []config.APIKey) (*APIKeys, error) {
+	a := &APIKeys{keys: make(map[[sha256.Size]byte]Principal, len(keys))}
+
+	for _, key := range keys {
+		if key.KeyFile == "" {
+			return nil, fmt.Errorf("api key %q has no key_file", key.Name)
+		}
+		b, err := os.ReadFile(key.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read api key %q: %w", key.Name, err)
+		}
+		value := strings.TrimSpace(string(b))
+		if value == "" {
+			return nil, fmt.Errorf("api key %q is empty", key.Name)
+		}
+
+		digest := sha256.Sum256([]byte(value))
+		if _, ok := a.keys[digest]; ok {
+			return nil, fmt.Errorf("api key %q duplicates another key", key.Name)
+		}
+		a.keys[digest] = Principal{Subject: "api_key:" + key.Name, Scopes: key.Scopes}
+	}
+
+	return a, nil
+}
+
+func (a *APIKeys) Authenticate(r *http.Request) (Principal, error) {
+	key := r.Header.Get(APIKeyHeader)
+	if key == "" {
+		return Principal{}, ErrNoCredentials
+	}
+
+	principal, ok := a.keys[sha256.Sum256([]byte(key))]
+	if !ok {
+		return Principal{}, errors.New("unknown api key")
+	}
+	return principal, nil
+}
@@ -0,0 +1,88 @@
+package authMiddleware
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	requestIDMiddleware "wbnats/internal/controller/http-server/middleware/requestid"
+	"wbnats/internal/controller/http-server/problem"
+	"wbnats/internal/controller/http-server/scope"
+	"wbnats/internal/lib/logger/sl"
+)
+
+// ErrNoCredentials means the request carries no credentials the authenticator
+// understands, so the next one is tried.
+var ErrNoCredentials = errors.New("no credentials")
+
+const subjectKey = "auth_subject"
+
+type Principal struct {
+	Subject string
+	Scopes  []string
+}
+
+// Authenticator checks the credentials of a request.
+type Authenticator interface {
+	Authenticate(r *http.Request) (Principal, error)
+}
+
+// New authenticates requests with the first authenticator that recognises
+// their credentials and stores the granted scopes for Require. Requests
+// without valid credentials get 401.
+func New(log *slog.Logger, authenticators ...Authenticator) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		for _, authenticator := range authenticators {
+			principal, err := authenticator.Authenticate(c.Request)
+			if errors.Is(err, ErrNoCredentials) {
+				continue
+			}
+			if err != nil {
+				log.Warn("authentication failed",
+					slog.String("request_id", requestIDMiddleware.FromContext(c)),
+					sl.Err(err),
+				)
+				unauthorized(c, "invalid credentials")
+				return
+			}
+
+			c.Set(subjectKey, principal.Subject)
+			scope.Set(c, principal.Scopes)
+			c.Next()
+			return
+		}
+
+		unauthorized(c, "authentication required")
+	}
+}
+
+// Anonymous grants scopes to every request, used when auth is disabled.
+func Anonymous(scopes ...string) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		c.Set(subjectKey, "anonymous")
+		scope.Set(c, scopes)
+		c.Next()
+	}
+}
+
+// Require lets the request through only if it was granted every given scope.
+func Require(scopes ...string) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		for _, s := range scopes {
+			if !scope.Has(c, s) {
+				problem.Write(c, http.StatusForbidden, "missing scope "+s)
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+func Subject(c *gin.Context) string {
+	return c.GetString(subjectKey)
+}
+
+func unauthorized(c *gin.Context, detail string) {
+	c.Header("WWW-Authenticate", `Bearer, ApiKey`)
+	problem.Write(c, http.StatusUnauthorized, detail)
+}
@@ -0,0 +1,133 @@
+package authMiddleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"wbnats/internal/config"
+	"wbnats/internal/controller/http-server/scope"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// writeFile stores content in a file of a temporary directory.
+func writeFile(t *testing.T, name string, content []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// serve runs a request through the auth middleware and Require(scopes...).
+func serve(t *testing.T, authenticate func(c *gin.Context), required []string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+
+	r := gin.New()
+	r.GET("/orders", authenticate, Require(required...), func(c *gin.Context) {
+		c.String(http.StatusOK, Subject(c))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func newTestAPIKeys(t *testing.T) *APIKeys {
+	t.Helper()
+
+	keys, err := NewAPIKeys([]config.APIKey{
+		{Name: "reader", KeyFile: writeFile(t, "reader", []byte("reader-key\n")), Scopes: []string{scope.Read}},
+		{Name: "admin", KeyFile: writeFile(t, "admin", []byte("admin-key")), Scopes: []string{scope.Admin}},
+	})
+	if err != nil {
+		t.Fatalf("NewAPIKeys() error = %v", err)
+	}
+	return keys
+}
+
+func TestAPIKeys(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	authenticate := New(log, newTestAPIKeys(t))
+
+	tests := []struct {
+		name     string
+		key      string
+		required []string
+		want     int
+		subject  string
+	}{
+		{name: "known key", key: "reader-key", required: []string{scope.Read}, want: http.StatusOK, subject: "api_key:reader"},
+		{name: "unknown key", key: "guessed-key", required: []string{scope.Read}, want: http.StatusUnauthorized},
+		{name: "no credentials", required: []string{scope.Read}, want: http.StatusUnauthorized},
+		{name: "missing scope", key: "reader-key", required: []string{scope.ReadPII}, want: http.StatusForbidden},
+		{name: "admin implies every scope", key: "admin-key", required: []string{scope.Read, scope.ReadPII}, want: http.StatusOK, subject: "api_key:admin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.key != "" {
+				header.Set(APIKeyHeader, tt.key)
+			}
+
+			rec := serve(t, authenticate, tt.required, header)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			if tt.want == http.StatusOK && rec.Body.String() != tt.subject {
+				t.Errorf("subject = %q, want %q", rec.Body, tt.subject)
+			}
+			if tt.want == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 without WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestNewAPIKeysRejectsBadKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []config.APIKey
+	}{
+		{"no key file", []config.APIKey{{Name: "a"}}},
+		{"missing key file", []config.APIKey{{Name: "a", KeyFile: filepath.Join(t.TempDir(), "missing")}}},
+		{"empty key", []config.APIKey{{Name: "a", KeyFile: writeFile(t, "a", []byte(" \n"))}}},
+		{"duplicate key", []config.APIKey{
+			{Name: "a", KeyFile: writeFile(t, "a", []byte("same"))},
+			{Name: "b", KeyFile: writeFile(t, "b", []byte("same"))},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewAPIKeys(tt.keys); err == nil {
+				t.Error("NewAPIKeys() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestAnonymous(t *testing.T) {
+	authenticate := Anonymous(scope.Read)
+
+	if rec := serve(t, authenticate, []string{scope.Read}, nil); rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec := serve(t, authenticate, []string{scope.ReadPII}, nil); rec.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
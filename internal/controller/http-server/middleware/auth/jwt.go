@@ -0,0 +1,89 @@
+package authMiddleware
+
+import (
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"net/http"
+	"os"
+	"strings"
+	"wbnats/internal/config"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+)
+
+// JWT authenticates bearer tokens. Scopes come from the space separated
+// "scope" claim or the "scopes" array claim.
+type JWT struct {
+	key    any
+	parser *jwt.Parser
+}
+
+func NewJWT(cfg config.JWTConfig) (*JWT, error) {
+	var key any
+	switch cfg.Algorithm {
+	case AlgorithmHS256:
+		b, err := os.ReadFile(cfg.SecretFile)
+		if err != nil {
+			return nil, fmt.Errorf("read jwt secret: %w", err)
+		}
+		secret := []byte(strings.TrimSpace(string(b)))
+		if len(secret) < 32 {
+			return nil, errors.New("jwt secret must be at least 32 bytes")
+		}
+		key = secret
+	case AlgorithmRS256:
+		b, err := os.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read jwt public key: %w", err)
+		}
+		key, err = jwt.ParseRSAPublicKeyFromPEM(b)
+		if err != nil {
+			return nil, fmt.Errorf("parse jwt public key: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm %q", cfg.Algorithm)
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{cfg.Algorithm}),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	return &JWT{key: key, parser: jwt.NewParser(opts...)}, nil
+}
+
+type claims struct {
+	jwt.RegisteredClaims
+	Scope  string   `json:"scope"`
+	Scopes []string `json:"scopes"`
+}
+
+func (j *JWT) Authenticate(r *http.Request) (Principal, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return Principal{}, ErrNoCredentials
+	}
+
+	c := &claims{}
+	_, err := j.parser.ParseWithClaims(token, c, func(*jwt.Token) (any, error) {
+		return j.key, nil
+	})
+	if err != nil {
+		return Principal{}, err
+	}
+
+	return Principal{
+		Subject: c.Subject,
+		Scopes:  append(strings.Fields(c.Scope), c.Scopes...),
+	}, nil
+}
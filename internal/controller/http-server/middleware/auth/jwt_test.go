@@ -0,0 +1,222 @@
+package authMiddleware
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+	"wbnats/internal/config"
+	"wbnats/internal/controller/http-server/scope"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+var rsaKey = sync.OnceValue(func() *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return key
+})
+
+func publicKeyPEM(t *testing.T) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(&rsaKey().PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func newTestJWT(t *testing.T, algorithm string) *JWT {
+	t.Helper()
+
+	cfg := config.JWTConfig{Algorithm: algorithm, Issuer: "auth.example", Audience: "orders"}
+	switch algorithm {
+	case AlgorithmHS256:
+		cfg.SecretFile = writeFile(t, "secret", []byte(testSecret))
+	case AlgorithmRS256:
+		cfg.PublicKeyFile = writeFile(t, "public.pem", publicKeyPEM(t))
+	}
+
+	j, err := NewJWT(cfg)
+	if err != nil {
+		t.Fatalf("NewJWT() error = %v", err)
+	}
+	return j
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   "client-1",
+		"iss":   "auth.example",
+		"aud":   "orders",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": scope.Read,
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key any, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func bearer(token string) http.Header {
+	return http.Header{"Authorization": {"Bearer " + token}}
+}
+
+func TestJWT(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	hs256 := New(log, newTestJWT(t, AlgorithmHS256))
+	rs256 := New(log, newTestJWT(t, AlgorithmRS256))
+
+	without := func(claim string) jwt.MapClaims {
+		claims := validClaims()
+		delete(claims, claim)
+		return claims
+	}
+	with := func(claim string, value any) jwt.MapClaims {
+		claims := validClaims()
+		claims[claim] = value
+		return claims
+	}
+
+	tests := []struct {
+		name         string
+		authenticate func(c *gin.Context)
+		token        string
+		required     []string
+		want         int
+	}{
+		{
+			name:         "valid hs256",
+			authenticate: hs256,
+			token:        sign(t, jwt.SigningMethodHS256, []byte(testSecret), validClaims()),
+			want:         http.StatusOK,
+		},
+		{
+			name:         "valid rs256",
+			authenticate: rs256,
+			token:        sign(t, jwt.SigningMethodRS256, rsaKey(), validClaims()),
+			want:         http.StatusOK,
+		},
+		{
+			name:         "rs256 token when hs256 is configured",
+			authenticate: hs256,
+			token:        sign(t, jwt.SigningMethodRS256, rsaKey(), validClaims()),
+			want:         http.StatusUnauthorized,
+		},
+		{
+			name:         "hs256 token signed with the rs256 public key",
+			authenticate: rs256,
+			token:        sign(t, jwt.SigningMethodHS256, publicKeyPEM(t), validClaims()),
+			want:         http.StatusUnauthorized,
+		},
+		{
+			name:         "wrong secret",
+			authenticate: hs256,
+			token:        sign(t, jwt.SigningMethodHS256, []byte(testSecret+"x"), validClaims()),
+			want:         http.StatusUnauthorized,
+		},
+		{
+			name:         "no expiration",
+			authenticate: hs256,
+			token:        sign(t, jwt.SigningMethodHS256, []byte(testSecret), without("exp")),
+			want:         http.StatusUnauthorized,
+		},
+		{
+			name:         "expired",
+			authenticate: hs256,
+			token:        sign(t, jwt.SigningMethodHS256, []byte(testSecret), with("exp", time.Now().Add(-time.Minute).Unix())),
+			want:         http.StatusUnauthorized,
+		},
+		{
+			name:         "wrong issuer",
+			authenticate: hs256,
+			token:        sign(t, jwt.SigningMethodHS256, []byte(testSecret), with("iss", "evil.example")),
+			want:         http.StatusUnauthorized,
+		},
+		{
+			name:         "no issuer",
+			authenticate: hs256,
+			token:        sign(t, jwt.SigningMethodHS256, []byte(testSecret), without("iss")),
+			want:         http.StatusUnauthorized,
+		},
+		{
+			name:         "wrong audience",
+			authenticate: rs256,
+			token:        sign(t, jwt.SigningMethodRS256, rsaKey(), with("aud", "billing")),
+			want:         http.StatusUnauthorized,
+		},
+		{
+			name:         "missing scope",
+			authenticate: hs256,
+			token:        sign(t, jwt.SigningMethodHS256, []byte(testSecret), validClaims()),
+			required:     []string{scope.ReadPII},
+			want:         http.StatusForbidden,
+		},
+		{
+			name:         "scopes array claim",
+			authenticate: hs256,
+			token:        sign(t, jwt.SigningMethodHS256, []byte(testSecret), with("scopes", []string{scope.ReadPII})),
+			required:     []string{scope.Read, scope.ReadPII},
+			want:         http.StatusOK,
+		},
+		{
+			name:         "malformed token",
+			authenticate: hs256,
+			token:        "not-a-jwt",
+			want:         http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			required := tt.required
+			if required == nil {
+				required = []string{scope.Read}
+			}
+
+			rec := serve(t, tt.authenticate, required, bearer(tt.token))
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			if tt.want == http.StatusOK && rec.Body.String() != "client-1" {
+				t.Errorf("subject = %q, want client-1", rec.Body)
+			}
+		})
+	}
+}
+
+func TestNewJWTRejectsWeakConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.JWTConfig
+	}{
+		{"short secret", config.JWTConfig{Algorithm: AlgorithmHS256, SecretFile: writeFile(t, "secret", []byte("short"))}},
+		{"not a public key", config.JWTConfig{Algorithm: AlgorithmRS256, PublicKeyFile: writeFile(t, "key.pem", []byte("garbage"))}},
+		{"unsupported algorithm", config.JWTConfig{Algorithm: "none"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewJWT(tt.cfg); err == nil {
+				t.Error("NewJWT() error = nil, want error")
+			}
+		})
+	}
+}
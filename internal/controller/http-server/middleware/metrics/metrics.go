@@ -0,0 +1,26 @@
+package metricsMiddleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"time"
+	"wbnats/internal/lib/metrics"
+)
+
+// New records request count and latency per route template, so /orders/:id
+// is one series rather than one per order.
+func New() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		metrics.HTTPDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,36 @@
+package requestIDMiddleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	Header = "X-Request-ID"
+
+	contextKey = "request_id"
+)
+
+func New() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		id := c.GetHeader(Header)
+		if id == "" || len(id) > 128 {
+			id = newID()
+		}
+
+		c.Set(contextKey, id)
+		c.Header(Header, id)
+		c.Next()
+	}
+}
+
+func FromContext(c *gin.Context) string {
+	return c.GetString(contextKey)
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -2,27 +2,25 @@ package timeoutMiddleware
 
 import (
 	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"time"
+	"wbnats/internal/controller/http-server/problem"
 )
 
+// New bounds the request context by timeout. Handlers run synchronously, so a
+// 504 is written only when the handler returned without writing anything.
 func New(timeout time.Duration) func(c *gin.Context) {
 	return func(c *gin.Context) {
-
 		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
-
-		defer func() {
-			if ctx.Err() == context.DeadlineExceeded {
-
-				c.Writer.WriteHeader(http.StatusGatewayTimeout)
-				c.Abort()
-			}
-
-			cancel()
-		}()
+		defer cancel()
 
 		c.Request = c.Request.WithContext(ctx)
 		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			problem.Write(c, http.StatusGatewayTimeout, "request timed out")
+		}
 	}
 }
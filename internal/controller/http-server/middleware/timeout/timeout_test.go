@@ -0,0 +1,94 @@
+package timeoutMiddleware
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wbnats/internal/controller/http-server/problem"
+)
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		handler func(c *gin.Context)
+		want    int
+	}{
+		{
+			name:    "handler in time",
+			handler: func(c *gin.Context) { c.String(http.StatusOK, "ok") },
+			want:    http.StatusOK,
+		},
+		{
+			name: "handler gave up on the deadline",
+			handler: func(c *gin.Context) {
+				<-c.Request.Context().Done()
+			},
+			want: http.StatusGatewayTimeout,
+		},
+		{
+			name: "handler wrote after the deadline",
+			handler: func(c *gin.Context) {
+				<-c.Request.Context().Done()
+				c.String(http.StatusServiceUnavailable, "late")
+			},
+			want: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(New(10 * time.Millisecond))
+			r.GET("/", tt.handler)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if tt.want == http.StatusGatewayTimeout && w.Header().Get("Content-Type") != problem.ContentType {
+				t.Errorf("Content-Type = %q, want %q", w.Header().Get("Content-Type"), problem.ContentType)
+			}
+		})
+	}
+}
+
+func TestNewSetsDeadline(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var deadline time.Time
+	var ok bool
+	r := gin.New()
+	r.Use(New(time.Minute))
+	r.GET("/", func(c *gin.Context) { deadline, ok = c.Request.Context().Deadline() })
+
+	start := time.Now()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if !ok || deadline.Before(start.Add(time.Minute-time.Second)) || deadline.After(time.Now().Add(time.Minute)) {
+		t.Errorf("deadline = %v (set %v), want about a minute from %v", deadline, ok, start)
+	}
+}
+
+func TestNewIgnoresClientCancel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(New(time.Minute))
+	r.GET("/", func(c *gin.Context) {})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+
+	if w.Code == http.StatusGatewayTimeout {
+		t.Errorf("status = %d, want no timeout for a cancelled request", w.Code)
+	}
+}
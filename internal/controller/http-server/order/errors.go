@@ -0,0 +1,49 @@
+package orderHTTPHandler
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	requestIDMiddleware "wbnats/internal/controller/http-server/middleware/requestid"
+	"wbnats/internal/controller/http-server/problem"
+	"wbnats/internal/lib/logger/sl"
+	orderService "wbnats/internal/services/order"
+)
+
+// statusClientClosedRequest is the nginx convention for requests the client
+// gave up on; nothing is sent, the code only shows in logs and metrics.
+const statusClientClosedRequest = 499
+
+// writeError maps orderService errors to problem+json responses. Unexpected
+// errors are logged and reported without internal details.
+func writeError(c *gin.Context, log *slog.Logger, err error) {
+	switch {
+	case errors.Is(err, orderService.ErrInvalidID):
+		problem.Write(c, http.StatusBadRequest, "order id is malformed")
+	case errors.Is(err, orderService.ErrNotFound):
+		problem.Write(c, http.StatusNotFound, "order not found")
+	case errors.Is(err, orderService.ErrDeadlineExceeded):
+		problem.Write(c, http.StatusGatewayTimeout, "request timed out")
+	case errors.Is(err, orderService.ErrUnavailable):
+		log.Warn("order storage unavailable",
+			slog.String("request_id", requestIDMiddleware.FromContext(c)),
+			sl.Err(err),
+		)
+		c.Header("Retry-After", "5")
+		problem.Write(c, http.StatusServiceUnavailable, "order storage is temporarily unavailable")
+	case errors.Is(err, context.Canceled):
+		log.Debug("client closed request",
+			slog.String("request_id", requestIDMiddleware.FromContext(c)),
+			sl.Err(err),
+		)
+		c.AbortWithStatus(statusClientClosedRequest)
+	default:
+		log.Error("failed to handle request",
+			slog.String("request_id", requestIDMiddleware.FromContext(c)),
+			sl.Err(err),
+		)
+		problem.Write(c, http.StatusInternalServerError, "internal error")
+	}
+}
@@ -0,0 +1,102 @@
+package orderHTTPHandler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"wbnats/internal/controller/http-server/problem"
+	orderService "wbnats/internal/services/order"
+)
+
+func TestWriteError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantLevel  string
+		retryAfter bool
+	}{
+		{name: "invalid id", err: orderService.ErrInvalidID, wantStatus: http.StatusBadRequest},
+		{name: "not found", err: fmt.Errorf("Order.Order: %w", orderService.ErrNotFound), wantStatus: http.StatusNotFound},
+		{
+			name:       "deadline exceeded",
+			err:        fmt.Errorf("%w: %w", orderService.ErrDeadlineExceeded, context.DeadlineExceeded),
+			wantStatus: http.StatusGatewayTimeout,
+		},
+		{
+			name:       "storage unavailable",
+			err:        fmt.Errorf("Order.Order: %w", orderService.ErrUnavailable),
+			wantStatus: http.StatusServiceUnavailable,
+			wantLevel:  "WARN",
+			retryAfter: true,
+		},
+		{
+			name:       "client went away",
+			err:        fmt.Errorf("Order.Order: %w", context.Canceled),
+			wantStatus: statusClientClosedRequest,
+			wantLevel:  "DEBUG",
+		},
+		{
+			name:       "unexpected",
+			err:        errors.New("pq: relation does not exist"),
+			wantStatus: http.StatusInternalServerError,
+			wantLevel:  "ERROR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			log := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+			r := gin.New()
+			r.GET("/v1/orders/:id", func(c *gin.Context) { writeError(c, log, tt.err) })
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/v1/orders/b563feb7b2b84b6test", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+
+			if tt.wantStatus == statusClientClosedRequest {
+				if w.Body.Len() != 0 {
+					t.Errorf("body = %q, want none", w.Body)
+				}
+			} else {
+				var p problem.Problem
+				if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+					t.Fatalf("body is not a problem: %v", err)
+				}
+				if p.Status != tt.wantStatus {
+					t.Errorf("problem status = %d, want %d", p.Status, tt.wantStatus)
+				}
+				if strings.Contains(p.Detail, "pq:") {
+					t.Errorf("problem leaks the cause: %q", p.Detail)
+				}
+			}
+
+			if got := w.Header().Get("Retry-After") != ""; got != tt.retryAfter {
+				t.Errorf("Retry-After set = %v, want %v", got, tt.retryAfter)
+			}
+
+			if tt.wantLevel == "" {
+				if logs.Len() != 0 {
+					t.Errorf("logged %q, want nothing", logs.String())
+				}
+			} else if !strings.Contains(logs.String(), "level="+tt.wantLevel) {
+				t.Errorf("logged %q, want level %s", logs.String(), tt.wantLevel)
+			}
+		})
+	}
+}
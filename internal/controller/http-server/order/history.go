@@ -0,0 +1,20 @@
+package orderHTTPHandler
+
+import (
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	orderService "wbnats/internal/services/order"
+)
+
+func NewOrderHistoryHandler(log *slog.Logger, order *orderService.Order) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		uid := c.Param("id")
+		history, err := (*order).History(c.Request.Context(), uid)
+		if err != nil {
+			writeError(c, log, err)
+			return
+		}
+		c.JSON(http.StatusOK, toStatusHistoryResponse(uid, history))
+	}
+}
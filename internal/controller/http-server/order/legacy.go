@@ -0,0 +1,89 @@
+package orderHTTPHandler
+
+import (
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"wbnats/internal/controller/http-server/problem"
+	"wbnats/internal/lib/pii"
+	orderService "wbnats/internal/services/order"
+)
+
+// NewLegacyOrderHandler serves GET /orders/:id in the format it had before
+// versioning, for clients not yet moved to /v1. Responses carry Deprecation
+// and Link headers pointing to the successor.
+//
+// Deprecated: to be removed one release after /v1 shipped.
+func NewLegacyOrderHandler(log *slog.Logger, order *orderService.Order, policy pii.Policy) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		uid := c.Param("id")
+
+		deprecate(c, "/v1/orders/"+url.PathEscape(uid))
+
+		policy, err := requestPolicy(c, policy)
+		if err != nil {
+			return
+		}
+
+		ord, err := (*order).Order(c.Request.Context(), uid)
+		if err != nil {
+			writeError(c, log, err)
+			return
+		}
+
+		maskLegacyDelivery(&ord.Delivery, policy)
+
+		c.JSON(http.StatusOK, ord)
+	}
+}
+
+// NewLegacyOrdersHandler serves GET /orders in the format it had before
+// versioning. It takes the same query parameters as the /v1 list.
+//
+// Deprecated: to be removed one release after /v1 shipped.
+func NewLegacyOrdersHandler(log *slog.Logger, order *orderService.Order, policy pii.Policy) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		successor := "/v1/orders"
+		if c.Request.URL.RawQuery != "" {
+			successor += "?" + c.Request.URL.RawQuery
+		}
+		deprecate(c, successor)
+
+		policy, err := requestPolicy(c, policy)
+		if err != nil {
+			return
+		}
+
+		filter, err := parseOrderFilter(c)
+		if err != nil {
+			problem.Write(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		page, err := (*order).Orders(c.Request.Context(), filter)
+		if err != nil {
+			writeError(c, log, err)
+			return
+		}
+
+		for i := range page.Orders {
+			maskLegacyDelivery(&page.Orders[i].Delivery, policy)
+		}
+
+		nextCursor := ""
+		if page.Next != nil {
+			nextCursor = encodeCursor(page.Next)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"orders":      page.Orders,
+			"next_cursor": nextCursor,
+		})
+	}
+}
+
+func deprecate(c *gin.Context, successor string) {
+	c.Header("Deprecation", "true")
+	c.Header("Link", "<"+successor+`>; rel="successor-version"`)
+}
@@ -0,0 +1,138 @@
+package orderHTTPHandler
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wbnats/internal/controller/http-server/scope"
+	"wbnats/internal/lib/pii"
+	orderService "wbnats/internal/services/order"
+	"wbnats/internal/services/order/models"
+)
+
+type fakeOrders struct {
+	order  models.Order
+	filter models.OrderFilter
+}
+
+func (f *fakeOrders) Order(context.Context, string) (models.Order, error) {
+	return f.order, nil
+}
+
+func (f *fakeOrders) ListOrders(_ context.Context, filter models.OrderFilter) (models.OrderPage, error) {
+	f.filter = filter
+	return models.OrderPage{
+		Orders: []models.Order{f.order},
+		Next:   &models.OrderCursor{UID: f.order.UID},
+	}, nil
+}
+
+func newLegacyRouter(orders *fakeOrders, scopes ...string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := orderService.New(log, nil, orders, orders, nil, nil, nil)
+	policy := pii.Policy{Name: pii.ModePartial, Phone: pii.ModePartial, Email: pii.ModePartial, Address: pii.ModeFull, Zip: pii.ModeNone}
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) { scope.Set(c, scopes) })
+	r.GET("/orders", NewLegacyOrdersHandler(log, service, policy))
+	r.GET("/orders/:id", NewLegacyOrderHandler(log, service, policy))
+	return r
+}
+
+func legacyOrder() models.Order {
+	return models.Order{
+		UID: "b563feb7b2b84b6test",
+		Delivery: models.Delivery{
+			Name:    "Test Testov",
+			Phone:   "+79720001234",
+			Email:   "test@gmail.com",
+			Address: "Ploshad Mira 15",
+			Zip:     "2639809",
+		},
+	}
+}
+
+func TestLegacyOrdersHandler(t *testing.T) {
+	orders := &fakeOrders{order: legacyOrder()}
+
+	w := httptest.NewRecorder()
+	newLegacyRouter(orders, scope.Read).ServeHTTP(w, httptest.NewRequest("GET", "/orders?customer_id=test&limit=10", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	if got := w.Header().Get("Deprecation"); got != "true" {
+		t.Errorf("Deprecation = %q, want true", got)
+	}
+	if got, want := w.Header().Get("Link"), `</v1/orders?customer_id=test&limit=10>; rel="successor-version"`; got != want {
+		t.Errorf("Link = %q, want %q", got, want)
+	}
+	if orders.filter.CustomerID != "test" || orders.filter.Limit != 10 {
+		t.Errorf("filter = %+v, want customer_id and limit from the query", orders.filter)
+	}
+
+	var body struct {
+		Orders     []models.Order `json:"orders"`
+		NextCursor string         `json:"next_cursor"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Orders) != 1 || body.NextCursor == "" {
+		t.Fatalf("body = %+v, want one order and a cursor", body)
+	}
+	if d := body.Orders[0].Delivery; d.Phone != "+7***1234" || d.Address != "***" || d.Zip != "2639809" {
+		t.Errorf("delivery = %+v, want it masked", d)
+	}
+}
+
+func TestLegacyOrdersHandlerRejectsBadFilter(t *testing.T) {
+	w := httptest.NewRecorder()
+	newLegacyRouter(&fakeOrders{}, scope.Read).ServeHTTP(w, httptest.NewRequest("GET", "/orders?limit=0", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestLegacyOrderHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		scopes []string
+		phone  string
+	}{
+		{name: "masked", scopes: []string{scope.Read}, phone: "+7***1234"},
+		{name: "unmasked", query: "?unmasked=true", scopes: []string{scope.Read, scope.ReadPII}, phone: "+79720001234"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			newLegacyRouter(&fakeOrders{order: legacyOrder()}, tt.scopes...).
+				ServeHTTP(w, httptest.NewRequest("GET", "/orders/b563feb7b2b84b6test"+tt.query, nil))
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+			if got, want := w.Header().Get("Link"), `</v1/orders/b563feb7b2b84b6test>; rel="successor-version"`; got != want {
+				t.Errorf("Link = %q, want %q", got, want)
+			}
+
+			var ord models.Order
+			if err := json.Unmarshal(w.Body.Bytes(), &ord); err != nil {
+				t.Fatal(err)
+			}
+			if ord.Delivery.Phone != tt.phone {
+				t.Errorf("phone = %q, want %q", ord.Delivery.Phone, tt.phone)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package orderHTTPHandler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"wbnats/internal/controller/http-server/problem"
+	"wbnats/internal/lib/pii"
+	orderService "wbnats/internal/services/order"
+	"wbnats/internal/services/order/models"
+)
+
+func NewOrdersHandler(log *slog.Logger, order *orderService.Order, policy pii.Policy) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		policy, err := requestPolicy(c, policy)
+		if err != nil {
+			return
+		}
+
+		filter, err := parseOrderFilter(c)
+		if err != nil {
+			problem.Write(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		page, err := (*order).Orders(c.Request.Context(), filter)
+		if err != nil {
+			writeError(c, log, err)
+			return
+		}
+
+		nextCursor := ""
+		if page.Next != nil {
+			nextCursor = encodeCursor(page.Next)
+		}
+
+		c.JSON(http.StatusOK, toOrderListResponse(page, nextCursor, policy))
+	}
+}
+
+func parseOrderFilter(c *gin.Context) (models.OrderFilter, error) {
+	filter := models.OrderFilter{
+		CustomerID:      c.Query("customer_id"),
+		TrackNumber:     c.Query("track_number"),
+		DeliveryService: c.Query("delivery_service"),
+		Provider:        c.Query("provider"),
+		Currency:        c.Query("currency"),
+	}
+
+	if v := c.Query("date_from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, fmt.Errorf("date_from must be an RFC 3339 timestamp")
+		}
+		filter.CreatedFrom = t
+	}
+
+	if v := c.Query("date_to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, fmt.Errorf("date_to must be an RFC 3339 timestamp")
+		}
+		filter.CreatedTo = t
+	}
+
+	switch sort := models.SortOrder(c.Query("sort")); sort {
+	case "", models.SortCreatedAsc, models.SortCreatedDesc:
+		filter.Sort = sort
+	default:
+		return filter, fmt.Errorf("sort must be %q or %q", models.SortCreatedAsc, models.SortCreatedDesc)
+	}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > orderService.MaxListLimit {
+			return filter, fmt.Errorf("limit must be between 1 and %d", orderService.MaxListLimit)
+		}
+		filter.Limit = limit
+	}
+
+	if v := c.Query("cursor"); v != "" {
+		cursor, err := decodeCursor(v)
+		if err != nil {
+			return filter, fmt.Errorf("cursor is invalid")
+		}
+		filter.After = cursor
+	}
+
+	return filter, nil
+}
+
+func encodeCursor(cursor *models.OrderCursor) string {
+	b, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (*models.OrderCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor := &models.OrderCursor{}
+	if err := json.Unmarshal(b, cursor); err != nil {
+		return nil, err
+	}
+	if cursor.UID == "" {
+		return nil, fmt.Errorf("cursor has no order uid")
+	}
+	return cursor, nil
+}
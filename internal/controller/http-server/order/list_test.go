@@ -0,0 +1,178 @@
+package orderHTTPHandler
+
+import (
+	"encoding/base64"
+	"github.com/gin-gonic/gin"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+	orderService "wbnats/internal/services/order"
+	"wbnats/internal/services/order/models"
+)
+
+func TestParseOrderFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cursor := &models.OrderCursor{
+		DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		UID:         "b563feb7b2b84b6test",
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		want    models.OrderFilter
+		wantErr bool
+	}{
+		{
+			name: "no parameters",
+		},
+		{
+			name:  "equality filters",
+			query: "customer_id=test&track_number=WBILMTESTTRACK&delivery_service=meest&provider=wbpay&currency=USD",
+			want: models.OrderFilter{
+				CustomerID:      "test",
+				TrackNumber:     "WBILMTESTTRACK",
+				DeliveryService: "meest",
+				Provider:        "wbpay",
+				Currency:        "USD",
+			},
+		},
+		{
+			name:  "date range",
+			query: "date_from=2021-11-01T00:00:00Z&date_to=2021-12-01T00:00:00%2B03:00",
+			want: models.OrderFilter{
+				CreatedFrom: time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC),
+				CreatedTo:   time.Date(2021, 11, 30, 21, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "date without time",
+			query:   "date_from=2021-11-01",
+			wantErr: true,
+		},
+		{
+			name:    "malformed date_to",
+			query:   "date_to=yesterday",
+			wantErr: true,
+		},
+		{
+			name:  "descending sort",
+			query: "sort=-date_created",
+			want:  models.OrderFilter{Sort: models.SortCreatedDesc},
+		},
+		{
+			name:    "unknown sort",
+			query:   "sort=amount",
+			wantErr: true,
+		},
+		{
+			name:  "limit",
+			query: "limit=50",
+			want:  models.OrderFilter{Limit: 50},
+		},
+		{
+			name:  "largest limit",
+			query: "limit=500",
+			want:  models.OrderFilter{Limit: orderService.MaxListLimit},
+		},
+		{
+			name:    "zero limit",
+			query:   "limit=0",
+			wantErr: true,
+		},
+		{
+			name:    "negative limit",
+			query:   "limit=-1",
+			wantErr: true,
+		},
+		{
+			name:    "limit above maximum",
+			query:   "limit=501",
+			wantErr: true,
+		},
+		{
+			name:    "limit not a number",
+			query:   "limit=ten",
+			wantErr: true,
+		},
+		{
+			name:  "cursor",
+			query: "cursor=" + encodeCursor(cursor),
+			want:  models.OrderFilter{After: cursor},
+		},
+		{
+			name:    "malformed cursor",
+			query:   "cursor=not-a-cursor",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/v1/orders?"+tt.query, nil)
+
+			got, err := parseOrderFilter(c)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseOrderFilter() = %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseOrderFilter() error = %v", err)
+			}
+			if !got.CreatedFrom.Equal(tt.want.CreatedFrom) || !got.CreatedTo.Equal(tt.want.CreatedTo) {
+				t.Errorf("parseOrderFilter() dates = %v..%v, want %v..%v",
+					got.CreatedFrom, got.CreatedTo, tt.want.CreatedFrom, tt.want.CreatedTo)
+			}
+			got.CreatedFrom, got.CreatedTo = tt.want.CreatedFrom, tt.want.CreatedTo
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseOrderFilter() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := &models.OrderCursor{
+		DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 123456000, time.UTC),
+		UID:         "b563feb7b2b84b6test",
+	}
+
+	encoded := encodeCursor(cursor)
+	got, err := decodeCursor(encoded)
+	if err != nil {
+		t.Fatalf("decodeCursor(%q) error = %v", encoded, err)
+	}
+	if !got.DateCreated.Equal(cursor.DateCreated) || got.UID != cursor.UID {
+		t.Errorf("decodeCursor(encodeCursor(%+v)) = %+v", cursor, got)
+	}
+}
+
+func TestDecodeCursorMalformed(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "!!!"},
+		{name: "padded base64", cursor: base64.URLEncoding.EncodeToString([]byte(`{"order_uid":"a"}`))},
+		{name: "not json", cursor: encode("order_uid=a")},
+		{name: "wrong type", cursor: encode(`{"order_uid":1}`)},
+		{name: "malformed date", cursor: encode(`{"date_created":"yesterday","order_uid":"a"}`)},
+		{name: "no uid", cursor: encode(`{"date_created":"2021-11-26T06:22:19Z"}`)},
+		{name: "empty object", cursor: encode(`{}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := decodeCursor(tt.cursor); err == nil {
+				t.Errorf("decodeCursor(%q) = %+v, want error", tt.cursor, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package orderHTTPHandler
+
+import (
+	orderHTTP "wbnats/internal/controller/http-server/order/models"
+	"wbnats/internal/lib/pii"
+	"wbnats/internal/services/order/models"
+)
+
+// toOrderResponse maps the domain order to the public response, keeping the
+// wire format stable while the domain model changes. Delivery data is masked
+// by policy.
+func toOrderResponse(order *models.Order, policy pii.Policy) orderHTTP.Order {
+	items := make([]orderHTTP.Item, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, orderHTTP.Item{
+			ChrtID:      item.ChrtID,
+			TrackNumber: item.TrackNumber,
+			Price:       item.Price,
+			RID:         item.RID,
+			Name:        item.Name,
+			Sale:        item.Sale,
+			Size:        item.Size,
+			TotalPrice:  item.TotalPrice,
+			NmID:        item.NmID,
+			Brand:       item.Brand,
+			Status:      item.Status,
+		})
+	}
+
+	resp := orderHTTP.Order{
+		UID:         order.UID,
+		TrackNumber: order.TrackNumber,
+		Entry:       order.Entry,
+		Delivery: orderHTTP.Delivery{
+			Name:    order.Delivery.Name,
+			Phone:   order.Delivery.Phone,
+			Zip:     order.Delivery.Zip,
+			City:    order.Delivery.City,
+			Address: order.Delivery.Address,
+			Region:  order.Delivery.Region,
+			Email:   order.Delivery.Email,
+		},
+		Payment: orderHTTP.Payment{
+			Transaction:  order.Payment.Transaction,
+			RequestID:    order.Payment.RequestID,
+			Currency:     order.Payment.Currency,
+			Provider:     order.Payment.Provider,
+			Amount:       order.Payment.Amount,
+			PaymentDT:    order.Payment.PaymentDT,
+			Bank:         order.Payment.Bank,
+			DeliveryCost: order.Payment.DeliveryCost,
+			GoodsTotal:   order.Payment.GoodsTotal,
+			CustomFee:    order.Payment.CustomFee,
+		},
+		Items:             items,
+		Locale:            order.Locale,
+		InternalSignature: order.InternalSignature,
+		CustomerID:        order.CustomerID,
+		DeliveryService:   order.DeliveryService,
+		Shardkey:          order.Shardkey,
+		SmID:              order.SmID,
+		DateCreated:       order.DateCreated.UTC().Format(orderHTTP.DateLayout),
+		OofShard:          order.OofShard,
+		Status:            string(order.Status),
+		Version:           order.Version,
+	}
+	maskDelivery(&resp.Delivery, policy)
+
+	return resp
+}
+
+func toOrderListResponse(page models.OrderPage, nextCursor string, policy pii.Policy) orderHTTP.OrderList {
+	orders := make([]orderHTTP.Order, 0, len(page.Orders))
+	for i := range page.Orders {
+		orders = append(orders, toOrderResponse(&page.Orders[i], policy))
+	}
+
+	return orderHTTP.OrderList{
+		Orders:     orders,
+		NextCursor: nextCursor,
+	}
+}
+
+func toStatusHistoryResponse(uid string, history []models.StatusChange) orderHTTP.StatusHistory {
+	changes := make([]orderHTTP.StatusChange, 0, len(history))
+	for _, change := range history {
+		changes = append(changes, orderHTTP.StatusChange{
+			From:      string(change.From),
+			Status:    string(change.Status),
+			ChangedAt: change.ChangedAt.UTC().Format(orderHTTP.DateLayout),
+			Reason:    change.Reason,
+		})
+	}
+
+	return orderHTTP.StatusHistory{
+		OrderUID: uid,
+		History:  changes,
+	}
+}
@@ -0,0 +1,13 @@
+package orderHTTP
+
+type StatusChange struct {
+	From      string `json:"from,omitempty"`
+	Status    string `json:"status"`
+	ChangedAt string `json:"changed_at"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+type StatusHistory struct {
+	OrderUID string         `json:"order_uid"`
+	History  []StatusChange `json:"history"`
+}
@@ -0,0 +1,15 @@
+package orderHTTP
+
+type Item struct {
+	ChrtID      int64  `json:"chrt_id"`
+	TrackNumber string `json:"track_number"`
+	Price       int64  `json:"price"`
+	RID         string `json:"rid"`
+	Name        string `json:"name"`
+	Sale        int16  `json:"sale"`
+	Size        string `json:"size"`
+	TotalPrice  int64  `json:"total_price"`
+	NmID        int64  `json:"nm_id"`
+	Brand       string `json:"brand"`
+	Status      int64  `json:"status"`
+}
@@ -0,0 +1,28 @@
+package orderHTTP
+
+// DateLayout is the date_created format, the same producers send over NATS.
+const DateLayout = "2006-01-02T15:04:05Z"
+
+type Order struct {
+	UID               string   `json:"order_uid"`
+	TrackNumber       string   `json:"track_number"`
+	Entry             string   `json:"entry"`
+	Delivery          Delivery `json:"delivery"`
+	Payment           Payment  `json:"payment"`
+	Items             []Item   `json:"items"`
+	Locale            string   `json:"locale"`
+	InternalSignature string   `json:"internal_signature"`
+	CustomerID        string   `json:"customer_id"`
+	DeliveryService   string   `json:"delivery_service"`
+	Shardkey          string   `json:"shardkey"`
+	SmID              int64    `json:"sm_id"`
+	DateCreated       string   `json:"date_created"`
+	OofShard          string   `json:"oof_shard"`
+	Status            string   `json:"status"`
+	Version           int64    `json:"version"`
+}
+
+type OrderList struct {
+	Orders     []Order `json:"orders"`
+	NextCursor string  `json:"next_cursor"`
+}
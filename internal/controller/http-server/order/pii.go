@@ -0,0 +1,51 @@
+package orderHTTPHandler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	orderHTTP "wbnats/internal/controller/http-server/order/models"
+	"wbnats/internal/controller/http-server/problem"
+	"wbnats/internal/controller/http-server/scope"
+	"wbnats/internal/lib/pii"
+	"wbnats/internal/services/order/models"
+)
+
+var errUnmaskedForbidden = errors.New("unmasked personal data requires the " + scope.ReadPII + " scope")
+
+// requestPolicy picks the masking policy of the request. Personal data is
+// masked unless the request asks for unmasked=true and holds the scope for it.
+// On error the problem response is already written.
+func requestPolicy(c *gin.Context, policy pii.Policy) (pii.Policy, error) {
+	v := c.Query("unmasked")
+	if v == "" {
+		return policy, nil
+	}
+
+	unmasked, err := strconv.ParseBool(v)
+	if err != nil {
+		err = fmt.Errorf("unmasked must be a boolean, got %q", v)
+		problem.Write(c, http.StatusBadRequest, err.Error())
+		return pii.Policy{}, err
+	}
+	if !unmasked {
+		return policy, nil
+	}
+
+	if !scope.Has(c, scope.ReadPII) {
+		problem.Write(c, http.StatusForbidden, errUnmaskedForbidden.Error())
+		return pii.Policy{}, errUnmaskedForbidden
+	}
+	return pii.Unmasked, nil
+}
+
+func maskDelivery(d *orderHTTP.Delivery, policy pii.Policy) {
+	policy.MaskDelivery(pii.DeliveryFields{Name: &d.Name, Phone: &d.Phone, Email: &d.Email, Address: &d.Address, Zip: &d.Zip})
+}
+
+// maskLegacyDelivery masks the domain delivery the legacy routes respond with.
+func maskLegacyDelivery(d *models.Delivery, policy pii.Policy) {
+	policy.MaskDelivery(pii.DeliveryFields{Name: &d.Name, Phone: &d.Phone, Email: &d.Email, Address: &d.Address, Zip: &d.Zip})
+}
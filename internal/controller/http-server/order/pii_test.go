@@ -0,0 +1,56 @@
+package orderHTTPHandler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wbnats/internal/controller/http-server/scope"
+	"wbnats/internal/lib/pii"
+)
+
+func TestRequestPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	masked := pii.Policy{Name: pii.ModePartial, Phone: pii.ModePartial, Email: pii.ModePartial, Address: pii.ModeFull, Zip: pii.ModeNone}
+
+	tests := []struct {
+		name   string
+		query  string
+		scopes []string
+		want   int
+		phone  pii.Mode
+	}{
+		{name: "masked by default", scopes: []string{scope.Read}, want: http.StatusOK, phone: pii.ModePartial},
+		{name: "unmasked without scope", query: "?unmasked=true", scopes: []string{scope.Read}, want: http.StatusForbidden},
+		{name: "unmasked with scope", query: "?unmasked=true", scopes: []string{scope.Read, scope.ReadPII}, want: http.StatusOK, phone: pii.ModeNone},
+		{name: "unmasked as admin", query: "?unmasked=1", scopes: []string{scope.Admin}, want: http.StatusOK, phone: pii.ModeNone},
+		{name: "explicitly masked", query: "?unmasked=false", scopes: []string{scope.Read}, want: http.StatusOK, phone: pii.ModePartial},
+		{name: "not a boolean", query: "?unmasked=please", scopes: []string{scope.ReadPII}, want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/orders/:id", func(c *gin.Context) {
+				scope.Set(c, tt.scopes)
+			}, func(c *gin.Context) {
+				policy, err := requestPolicy(c, masked)
+				if err != nil {
+					return
+				}
+				c.String(http.StatusOK, string(policy.Phone))
+			})
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders/1"+tt.query, nil))
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			if tt.want == http.StatusOK && rec.Body.String() != string(tt.phone) {
+				t.Errorf("phone mode = %q, want %q", rec.Body, tt.phone)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"log/slog"
 	"net/http"
+	"wbnats/internal/lib/pii"
 	orderService "wbnats/internal/services/order"
 	"wbnats/internal/services/order/models"
 )
@@ -13,16 +14,21 @@ type OrderService interface {
 	Order(ctx context.Context, uid string) (*models.Order, error)
 }
 
-func NewOrderHandler(log *slog.Logger, order *orderService.Order) func(c *gin.Context) {
+func NewOrderHandler(log *slog.Logger, order *orderService.Order, policy pii.Policy) func(c *gin.Context) {
 	return func(c *gin.Context) {
+		policy, err := requestPolicy(c, policy)
+		if err != nil {
+			return
+		}
+
 		uid := c.Param("id")
-		ord, err := (*order).Order(c, uid)
+		ord, err := (*order).Order(c.Request.Context(), uid)
 
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"message": "Order not found"})
+			writeError(c, log, err)
 			return
 		}
-		c.JSON(http.StatusOK, ord)
+		c.JSON(http.StatusOK, toOrderResponse(&ord, policy))
 		return
 	}
 }
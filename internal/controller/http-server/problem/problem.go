@@ -0,0 +1,38 @@
+package problem
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	requestIDMiddleware "wbnats/internal/controller/http-server/middleware/requestid"
+)
+
+const ContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details object.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Write aborts the request with a problem+json response unless a response
+// has already been written.
+func Write(c *gin.Context, status int, detail string) {
+	if c.Writer.Written() {
+		c.Abort()
+		return
+	}
+
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(status, Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		RequestID: requestIDMiddleware.FromContext(c),
+	})
+}
@@ -0,0 +1,28 @@
+package scope
+
+import (
+	"github.com/gin-gonic/gin"
+	"slices"
+)
+
+const (
+	// Read allows reading orders with personal data masked.
+	Read = "orders:read"
+	// ReadPII allows reading personal delivery data unmasked.
+	ReadPII = "orders:read_pii"
+	// Admin implies every other scope.
+	Admin = "admin"
+)
+
+const contextKey = "scopes"
+
+// Set stores the scopes granted to the request.
+func Set(c *gin.Context, scopes []string) {
+	c.Set(contextKey, scopes)
+}
+
+// Has reports whether the request was granted scope.
+func Has(c *gin.Context, scope string) bool {
+	scopes, _ := c.Value(contextKey).([]string)
+	return slices.Contains(scopes, scope) || slices.Contains(scopes, Admin)
+}
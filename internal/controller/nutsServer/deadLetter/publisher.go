@@ -0,0 +1,38 @@
+package deadLetterNatsStreaming
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"wbnats/internal/services/order/models"
+)
+
+type Conn interface {
+	Publish(subject string, data []byte) error
+}
+
+type Publisher struct {
+	conn    Conn
+	subject string
+}
+
+func NewPublisher(conn Conn, subject string) *Publisher {
+	return &Publisher{
+		conn:    conn,
+		subject: subject,
+	}
+}
+
+func (p *Publisher) SaveDeadLetter(_ context.Context, deadLetter *models.DeadLetter) error {
+	const op = "deadLetterNatsStreaming.SaveDeadLetter"
+
+	data, err := json.Marshal(deadLetter)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := p.conn.Publish(p.subject, data); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package orderNatsStreaming
+
+// OrderUpdate is the update message. Absent fields are left unchanged; items,
+// when present, replace all items of the order.
+type OrderUpdate struct {
+	UID               string          `json:"order_uid"`
+	Version           int64           `json:"version"`
+	TrackNumber       *string         `json:"track_number"`
+	Entry             *string         `json:"entry"`
+	Delivery          *DeliveryUpdate `json:"delivery"`
+	Payment           *PaymentUpdate  `json:"payment"`
+	Items             []Item          `json:"items"`
+	Locale            *string         `json:"locale"`
+	InternalSignature *string         `json:"internal_signature"`
+	CustomerID        *string         `json:"customer_id"`
+	DeliveryService   *string         `json:"delivery_service"`
+	Shardkey          *string         `json:"shardkey"`
+	SmID              *int64          `json:"sm_id"`
+	OofShard          *string         `json:"oof_shard"`
+}
+
+type DeliveryUpdate struct {
+	Name    *string `json:"name"`
+	Phone   *string `json:"phone"`
+	Zip     *string `json:"zip"`
+	City    *string `json:"city"`
+	Address *string `json:"address"`
+	Region  *string `json:"region"`
+	Email   *string `json:"email"`
+}
+
+type PaymentUpdate struct {
+	Transaction  *string `json:"transaction"`
+	RequestID    *string `json:"request_id"`
+	Currency     *string `json:"currency"`
+	Provider     *string `json:"provider"`
+	Amount       *int64  `json:"amount"`
+	PaymentDT    *int64  `json:"payment_dt"`
+	Bank         *string `json:"bank"`
+	DeliveryCost *int64  `json:"delivery_cost"`
+	GoodsTotal   *int64  `json:"goods_total"`
+	CustomFee    *int64  `json:"custom_fee"`
+}
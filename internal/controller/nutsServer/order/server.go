@@ -1,53 +1,71 @@
 package orderNatsStreaming
 
 import (
+	"context"
 	"encoding/json"
-	"github.com/nats-io/stan.go"
+	"errors"
 	"log/slog"
 	"time"
 	orderNatsStreaming "wbnats/internal/controller/nutsServer/order/models"
+	orderValidator "wbnats/internal/controller/nutsServer/order/validator"
+	settleNatsStreaming "wbnats/internal/controller/nutsServer/settle"
+	"wbnats/internal/lib/logger/sl"
+	"wbnats/internal/lib/message"
+	"wbnats/internal/lib/metrics"
 	orderService "wbnats/internal/services/order"
 	"wbnats/internal/services/order/models"
 )
 
 type OrderService interface {
-	NewOrder(order *models.Order)
+	NewOrder(ctx context.Context, order *models.Order) (models.SaveResult, error)
 }
 
-func NewOrderSaverHandler(log *slog.Logger, orderSaver *orderService.Order) func(*stan.Msg) {
-	return func(m *stan.Msg) {
+type DeadLetterSender interface {
+	Send(ctx context.Context, deadLetter *models.DeadLetter) error
+}
+
+// NewOrderSaverHandler validates and saves orders from any message source.
+// Permanent failures are dead-lettered, transient ones are nakked with a delay
+// growing with the number of redeliveries until the last delivery.
+func NewOrderSaverHandler(log *slog.Logger, orderSaver OrderService, deadLetters DeadLetterSender) func(message.Message) {
+	return func(m message.Message) {
+		settler := settleNatsStreaming.New(log, m, deadLetters, settleNatsStreaming.Hooks{
+			Retried: metrics.OrdersRetried.Inc,
+			Rejected: func(reason string) {
+				metrics.OrdersRejected.WithLabelValues(reason).Inc()
+			},
+		})
+
+		metrics.OrdersReceived.Inc()
 
 		newOrder := orderNatsStreaming.Order{}
 
-		err := json.Unmarshal(m.Data, &newOrder)
+		err := json.Unmarshal(m.Payload(), &newOrder)
 		if err != nil {
-			log.Error("failed to deserialization order", err)
+			log.Error("failed to deserialization order", sl.Err(err))
+			settler.Reject(models.DeadLetterReasonUnmarshal, err)
 			return
 		}
-		if newOrder.DateCreated == "" {
-			log.Error("failed to deserialization order", err)
+
+		if violations := orderValidator.Validate(&newOrder); len(violations) > 0 {
+			log.Error("order rejected by validation",
+				slog.String("orderUID", newOrder.UID),
+				slog.Int("violations", len(violations)),
+				slog.Any("details", []orderValidator.Violation(violations)),
+			)
+			settler.Reject(models.DeadLetterReasonValidation, violations)
 			return
 		}
-		dateCreated, err := time.Parse("2006-01-02T15:04:05Z", newOrder.DateCreated)
-
-		its := []models.Item{}
 
-		for _, item := range newOrder.Items {
-			its = append(its, models.Item{
-				ChrtID:      item.ChrtID,
-				TrackNumber: item.TrackNumber,
-				Price:       item.Price,
-				RID:         item.RID,
-				Name:        item.Name,
-				Sale:        item.Sale,
-				Size:        item.Size,
-				TotalPrice:  item.TotalPrice,
-				NmID:        item.NmID,
-				Brand:       item.Brand,
-				Status:      item.Status,
-			})
+		dateCreated, err := time.Parse(orderValidator.DateLayout, newOrder.DateCreated)
+		if err != nil {
+			log.Error("failed to parse order creation date", sl.Err(err))
+			settler.Reject(models.DeadLetterReasonValidation, err)
+			return
 		}
-		if err := (*orderSaver).NewOrder(&models.Order{
+		metrics.OrdersValidated.Inc()
+
+		result, err := orderSaver.NewOrder(context.Background(), &models.Order{
 			UID:         newOrder.UID,
 			TrackNumber: newOrder.TrackNumber,
 			Entry:       newOrder.Entry,
@@ -72,7 +90,7 @@ func NewOrderSaverHandler(log *slog.Logger, orderSaver *orderService.Order) func
 				GoodsTotal:   newOrder.Payment.GoodsTotal,
 				CustomFee:    newOrder.Payment.CustomFee,
 			},
-			Items:             its,
+			Items:             toItems(newOrder.Items),
 			Locale:            newOrder.Locale,
 			InternalSignature: newOrder.InternalSignature,
 			CustomerID:        newOrder.CustomerID,
@@ -81,11 +99,55 @@ func NewOrderSaverHandler(log *slog.Logger, orderSaver *orderService.Order) func
 			SmID:              newOrder.SmID,
 			DateCreated:       dateCreated,
 			OofShard:          newOrder.OofShard,
-		}); err != nil {
-			log.Error("failed to save order", err)
+		})
+		if err != nil {
+			if errors.Is(err, orderService.ErrUnavailable) {
+				settler.Retry(models.DeadLetterReasonSave, err)
+				return
+			}
+			if errors.Is(err, orderService.ErrConflict) {
+				log.Error("order conflicts with an already stored one",
+					slog.String("orderUID", newOrder.UID),
+					slog.String("result", result.String()),
+				)
+				settler.Reject(models.DeadLetterReasonConflict, err)
+				return
+			}
+			log.Error("failed to save order", sl.Err(err))
+			settler.Reject(models.DeadLetterReasonSave, err)
 			return
 		}
-		return
 
+		log.Info("order processed",
+			slog.String("orderUID", newOrder.UID),
+			slog.String("result", result.String()),
+		)
+		switch result {
+		case models.SaveResultInserted:
+			metrics.OrdersSaved.Inc()
+		case models.SaveResultDuplicate:
+			metrics.OrdersDuplicate.Inc()
+		}
+		settler.Ack()
+	}
+}
+
+func toItems(items []orderNatsStreaming.Item) []models.Item {
+	its := make([]models.Item, 0, len(items))
+	for _, item := range items {
+		its = append(its, models.Item{
+			ChrtID:      item.ChrtID,
+			TrackNumber: item.TrackNumber,
+			Price:       item.Price,
+			RID:         item.RID,
+			Name:        item.Name,
+			Sale:        item.Sale,
+			Size:        item.Size,
+			TotalPrice:  item.TotalPrice,
+			NmID:        item.NmID,
+			Brand:       item.Brand,
+			Status:      item.Status,
+		})
 	}
+	return its
 }
@@ -0,0 +1,248 @@
+package orderNatsStreaming
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+	orderNatsStreaming "wbnats/internal/controller/nutsServer/order/models"
+	"wbnats/internal/lib/message"
+	orderService "wbnats/internal/services/order"
+	"wbnats/internal/services/order/models"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+type fakeSaver struct {
+	result models.SaveResult
+	err    error
+	saved  *models.Order
+}
+
+func (f *fakeSaver) NewOrder(_ context.Context, order *models.Order) (models.SaveResult, error) {
+	f.saved = order
+	return f.result, f.err
+}
+
+type fakeDeadLetters struct {
+	err  error
+	sent []*models.DeadLetter
+}
+
+func (f *fakeDeadLetters) Send(_ context.Context, deadLetter *models.DeadLetter) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.sent = append(f.sent, deadLetter)
+	return nil
+}
+
+func (f *fakeDeadLetters) reason() string {
+	if len(f.sent) == 0 {
+		return ""
+	}
+	return f.sent[len(f.sent)-1].Reason
+}
+
+func orderPayload(t *testing.T, modify func(o *orderNatsStreaming.Order)) []byte {
+	t.Helper()
+
+	order := orderNatsStreaming.Order{
+		UID:         "b563feb7b2b84b6test",
+		TrackNumber: "WBILMTESTTRACK",
+		Entry:       "WBIL",
+		Delivery: orderNatsStreaming.Delivery{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			Zip:     "2639809",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Region:  "Kraiot",
+			Email:   "test@gmail.com",
+		},
+		Payment: orderNatsStreaming.Payment{
+			Transaction:  "b563feb7b2b84b6test",
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       1817,
+			PaymentDT:    1637907727,
+			Bank:         "alpha",
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+		},
+		Items: []orderNatsStreaming.Item{{
+			ChrtID:      9934930,
+			TrackNumber: "WBILMTESTTRACK",
+			Price:       453,
+			RID:         "ab4219087a764ae0btest",
+			Name:        "Mascaras",
+			Sale:        30,
+			Size:        "0",
+			TotalPrice:  317,
+			NmID:        2389212,
+			Brand:       "Vivienne Sabo",
+			Status:      202,
+		}},
+		Locale:          "en",
+		CustomerID:      "test",
+		DeliveryService: "meest",
+		Shardkey:        "9",
+		SmID:            99,
+		DateCreated:     "2021-11-26T06:22:19Z",
+		OofShard:        "1",
+	}
+	if modify != nil {
+		modify(&order)
+	}
+
+	b, err := json.Marshal(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// outcome describes how a handler settled a message.
+type outcome struct {
+	acked      bool
+	nakDelay   time.Duration
+	nakked     bool
+	deadLetter string
+}
+
+func settled(m *message.Local, deadLetters *fakeDeadLetters) outcome {
+	delay, nakked := m.Nakked()
+	return outcome{acked: m.Acked(), nakDelay: delay, nakked: nakked, deadLetter: deadLetters.reason()}
+}
+
+func TestOrderSaverHandler(t *testing.T) {
+	unavailable := fmt.Errorf("save: %w", orderService.ErrUnavailable)
+
+	tests := []struct {
+		name          string
+		payload       func(t *testing.T) []byte
+		redeliveries  int
+		maxDeliveries int
+		result        models.SaveResult
+		err           error
+		deadLetterErr error
+		want          outcome
+	}{
+		{
+			name:   "saved",
+			result: models.SaveResultInserted,
+			want:   outcome{acked: true},
+		},
+		{
+			name:   "duplicate",
+			result: models.SaveResultDuplicate,
+			want:   outcome{acked: true},
+		},
+		{
+			name:    "malformed json",
+			payload: func(*testing.T) []byte { return []byte(`{"order_uid":`) },
+			want:    outcome{acked: true, deadLetter: models.DeadLetterReasonUnmarshal},
+		},
+		{
+			name: "invalid order",
+			payload: func(t *testing.T) []byte {
+				return orderPayload(t, func(o *orderNatsStreaming.Order) { o.Payment.Currency = "XXX" })
+			},
+			want: outcome{acked: true, deadLetter: models.DeadLetterReasonValidation},
+		},
+		{
+			name:   "conflict",
+			result: models.SaveResultConflict,
+			err:    fmt.Errorf("save: %w", orderService.ErrConflict),
+			want:   outcome{acked: true, deadLetter: models.DeadLetterReasonConflict},
+		},
+		{
+			name: "permanent save failure",
+			err:  errors.New("constraint violated"),
+			want: outcome{acked: true, deadLetter: models.DeadLetterReasonSave},
+		},
+		{
+			name: "storage unavailable",
+			err:  unavailable,
+			want: outcome{nakked: true, nakDelay: time.Second},
+		},
+		{
+			name:          "storage unavailable on a later delivery",
+			redeliveries:  3,
+			maxDeliveries: 5,
+			err:           unavailable,
+			want:          outcome{nakked: true, nakDelay: 8 * time.Second},
+		},
+		{
+			name:          "storage unavailable on the last delivery",
+			redeliveries:  4,
+			maxDeliveries: 5,
+			err:           unavailable,
+			want:          outcome{acked: true, deadLetter: models.DeadLetterReasonSave},
+		},
+		{
+			name:         "storage unavailable without a delivery limit",
+			redeliveries: 20,
+			err:          unavailable,
+			want:         outcome{nakked: true, nakDelay: 30 * time.Second},
+		},
+		{
+			name:          "dead letter not sent",
+			payload:       func(*testing.T) []byte { return []byte(`[]`) },
+			deadLetterErr: errors.New("dead letters unavailable"),
+			want:          outcome{nakked: true, nakDelay: time.Second},
+		},
+		{
+			name:          "dead letter not sent on the last delivery",
+			redeliveries:  4,
+			maxDeliveries: 5,
+			err:           unavailable,
+			deadLetterErr: errors.New("dead letters unavailable"),
+			want:          outcome{nakked: true, nakDelay: 16 * time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := orderPayload(t, nil)
+			if tt.payload != nil {
+				payload = tt.payload(t)
+			}
+
+			saver := &fakeSaver{result: tt.result, err: tt.err}
+			deadLetters := &fakeDeadLetters{err: tt.deadLetterErr}
+			m := message.NewLocal("orders", payload, nil).Redelivered(tt.redeliveries, tt.maxDeliveries)
+
+			NewOrderSaverHandler(discard, saver, deadLetters)(m)
+
+			if got := settled(m, deadLetters); got != tt.want {
+				t.Errorf("outcome = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderSaverHandlerMapsOrder(t *testing.T) {
+	saver := &fakeSaver{result: models.SaveResultInserted}
+	m := message.NewLocal("orders", orderPayload(t, nil), nil)
+
+	NewOrderSaverHandler(discard, saver, &fakeDeadLetters{})(m)
+
+	order := saver.saved
+	if order == nil {
+		t.Fatal("order was not saved")
+	}
+	if order.UID != "b563feb7b2b84b6test" || order.Delivery.Phone != "+9720000000" || order.Payment.Amount != 1817 {
+		t.Errorf("saved order = %+v", order)
+	}
+	if len(order.Items) != 1 || order.Items[0].ChrtID != 9934930 {
+		t.Errorf("saved items = %+v", order.Items)
+	}
+	if want := time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC); !order.DateCreated.Equal(want) {
+		t.Errorf("date created = %v, want %v", order.DateCreated, want)
+	}
+}
@@ -0,0 +1,131 @@
+package orderNatsStreaming
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	orderNatsStreaming "wbnats/internal/controller/nutsServer/order/models"
+	orderValidator "wbnats/internal/controller/nutsServer/order/validator"
+	settleNatsStreaming "wbnats/internal/controller/nutsServer/settle"
+	"wbnats/internal/lib/logger/sl"
+	"wbnats/internal/lib/message"
+	"wbnats/internal/lib/metrics"
+	orderService "wbnats/internal/services/order"
+	"wbnats/internal/services/order/models"
+)
+
+// maxNotFoundDeliveries bounds waiting for an order whose creation message
+// hasn't been processed yet.
+const maxNotFoundDeliveries = 5
+
+type OrderUpdater interface {
+	UpdateOrder(ctx context.Context, update *models.OrderUpdate) (models.Order, error)
+}
+
+// NewOrderUpdateHandler applies order update messages. Updates based on a
+// version other than the stored one are dead-lettered as stale.
+func NewOrderUpdateHandler(log *slog.Logger, orderUpdater OrderUpdater, deadLetters DeadLetterSender) func(message.Message) {
+	return func(m message.Message) {
+		settler := settleNatsStreaming.New(log, m, deadLetters, settleNatsStreaming.Hooks{
+			Retried: metrics.OrderUpdates.WithLabelValues("retried").Inc,
+			Rejected: func(string) {
+				metrics.OrderUpdates.WithLabelValues("rejected").Inc()
+			},
+		})
+
+		update := orderNatsStreaming.OrderUpdate{}
+		if err := json.Unmarshal(m.Payload(), &update); err != nil {
+			log.Error("failed to deserialization order update", sl.Err(err))
+			settler.Reject(models.DeadLetterReasonUnmarshal, err)
+			return
+		}
+
+		if violations := orderValidator.ValidateUpdate(&update); len(violations) > 0 {
+			log.Error("order update rejected by validation",
+				slog.String("orderUID", update.UID),
+				slog.Int("violations", len(violations)),
+				slog.Any("details", []orderValidator.Violation(violations)),
+			)
+			settler.Reject(models.DeadLetterReasonValidation, violations)
+			return
+		}
+
+		order, err := orderUpdater.UpdateOrder(context.Background(), toOrderUpdate(&update))
+		if err != nil {
+			switch {
+			case errors.Is(err, orderService.ErrUnavailable):
+				settler.Retry(models.DeadLetterReasonSave, err)
+			case errors.Is(err, orderService.ErrNotFound):
+				log.Warn("update for unknown order", slog.String("orderUID", update.UID))
+				settler.RetryUpTo(maxNotFoundDeliveries, models.DeadLetterReasonNotFound, err)
+			case errors.Is(err, orderService.ErrStaleVersion):
+				log.Error("stale order update", slog.String("orderUID", update.UID), sl.Err(err))
+				settler.Reject(models.DeadLetterReasonStale, err)
+			case errors.Is(err, orderService.ErrInvalidUpdate), errors.Is(err, orderService.ErrInvalidID):
+				log.Error("order update rejected", slog.String("orderUID", update.UID), sl.Err(err))
+				settler.Reject(models.DeadLetterReasonValidation, err)
+			default:
+				log.Error("failed to update order", sl.Err(err))
+				settler.Reject(models.DeadLetterReasonSave, err)
+			}
+			return
+		}
+
+		metrics.OrderUpdates.WithLabelValues("applied").Inc()
+		log.Info("order update processed",
+			slog.String("orderUID", order.UID),
+			slog.Int64("version", order.Version),
+		)
+		settler.Ack()
+	}
+}
+
+func toOrderUpdate(update *orderNatsStreaming.OrderUpdate) *models.OrderUpdate {
+	u := &models.OrderUpdate{
+		UID:               update.UID,
+		Version:           update.Version,
+		TrackNumber:       update.TrackNumber,
+		Entry:             update.Entry,
+		Locale:            update.Locale,
+		InternalSignature: update.InternalSignature,
+		CustomerID:        update.CustomerID,
+		DeliveryService:   update.DeliveryService,
+		Shardkey:          update.Shardkey,
+		SmID:              update.SmID,
+		OofShard:          update.OofShard,
+	}
+
+	if d := update.Delivery; d != nil {
+		u.Delivery = &models.DeliveryUpdate{
+			Name:    d.Name,
+			Phone:   d.Phone,
+			Zip:     d.Zip,
+			City:    d.City,
+			Address: d.Address,
+			Region:  d.Region,
+			Email:   d.Email,
+		}
+	}
+
+	if p := update.Payment; p != nil {
+		u.Payment = &models.PaymentUpdate{
+			Transaction:  p.Transaction,
+			RequestID:    p.RequestID,
+			Currency:     p.Currency,
+			Provider:     p.Provider,
+			Amount:       p.Amount,
+			PaymentDT:    p.PaymentDT,
+			Bank:         p.Bank,
+			DeliveryCost: p.DeliveryCost,
+			GoodsTotal:   p.GoodsTotal,
+			CustomFee:    p.CustomFee,
+		}
+	}
+
+	if update.Items != nil {
+		u.Items = toItems(update.Items)
+	}
+
+	return u
+}
@@ -0,0 +1,121 @@
+package orderNatsStreaming
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+	"wbnats/internal/lib/message"
+	orderService "wbnats/internal/services/order"
+	"wbnats/internal/services/order/models"
+)
+
+type fakeUpdater struct {
+	err     error
+	updated *models.OrderUpdate
+}
+
+func (f *fakeUpdater) UpdateOrder(_ context.Context, update *models.OrderUpdate) (models.Order, error) {
+	f.updated = update
+	if f.err != nil {
+		return models.Order{}, f.err
+	}
+	return models.Order{UID: update.UID, Version: update.Version + 1}, nil
+}
+
+func TestOrderUpdateHandler(t *testing.T) {
+	const update = `{"order_uid":"b563feb7b2b84b6test","version":2,"delivery":{"city":"Haifa"}}`
+	notFound := fmt.Errorf("update: %w", orderService.ErrNotFound)
+
+	tests := []struct {
+		name          string
+		payload       string
+		redeliveries  int
+		maxDeliveries int
+		err           error
+		want          outcome
+	}{
+		{
+			name:    "applied",
+			payload: update,
+			want:    outcome{acked: true},
+		},
+		{
+			name:    "malformed json",
+			payload: `{`,
+			want:    outcome{acked: true, deadLetter: models.DeadLetterReasonUnmarshal},
+		},
+		{
+			name:    "invalid update",
+			payload: `{"order_uid":"b563feb7b2b84b6test","version":0}`,
+			want:    outcome{acked: true, deadLetter: models.DeadLetterReasonValidation},
+		},
+		{
+			name:    "stale version",
+			payload: update,
+			err:     fmt.Errorf("update: %w", orderService.ErrStaleVersion),
+			want:    outcome{acked: true, deadLetter: models.DeadLetterReasonStale},
+		},
+		{
+			name:    "inconsistent result",
+			payload: update,
+			err:     fmt.Errorf("update: %w", orderService.ErrInvalidUpdate),
+			want:    outcome{acked: true, deadLetter: models.DeadLetterReasonValidation},
+		},
+		{
+			name:    "storage unavailable",
+			payload: update,
+			err:     fmt.Errorf("update: %w", orderService.ErrUnavailable),
+			want:    outcome{nakked: true, nakDelay: time.Second},
+		},
+		{
+			name:    "order not created yet",
+			payload: update,
+			err:     notFound,
+			want:    outcome{nakked: true, nakDelay: time.Second},
+		},
+		{
+			name:          "order still missing on the last delivery",
+			payload:       update,
+			redeliveries:  2,
+			maxDeliveries: 3,
+			err:           notFound,
+			want:          outcome{acked: true, deadLetter: models.DeadLetterReasonNotFound},
+		},
+		{
+			name:         "order still missing without a delivery limit",
+			payload:      update,
+			redeliveries: maxNotFoundDeliveries - 1,
+			err:          notFound,
+			want:         outcome{acked: true, deadLetter: models.DeadLetterReasonNotFound},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deadLetters := &fakeDeadLetters{}
+			m := message.NewLocal("orders.update", []byte(tt.payload), nil).Redelivered(tt.redeliveries, tt.maxDeliveries)
+
+			NewOrderUpdateHandler(discard, &fakeUpdater{err: tt.err}, deadLetters)(m)
+
+			if got := settled(m, deadLetters); got != tt.want {
+				t.Errorf("outcome = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderUpdateHandlerKeepsAbsentFields(t *testing.T) {
+	updater := &fakeUpdater{}
+	m := message.NewLocal("orders.update", []byte(`{"order_uid":"b563feb7b2b84b6test","version":2,"delivery":{"city":"Haifa"}}`), nil)
+
+	NewOrderUpdateHandler(discard, updater, &fakeDeadLetters{})(m)
+
+	u := updater.updated
+	if u == nil || u.Delivery == nil || u.Delivery.City == nil || *u.Delivery.City != "Haifa" {
+		t.Fatalf("update = %+v, want delivery city set", u)
+	}
+	if u.Delivery.Name != nil || u.Payment != nil || u.Items != nil || u.TrackNumber != nil {
+		t.Errorf("update = %+v, want absent fields left nil", u)
+	}
+}
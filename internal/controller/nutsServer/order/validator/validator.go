@@ -0,0 +1,294 @@
+package orderValidator
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+	orderNatsStreaming "wbnats/internal/controller/nutsServer/order/models"
+	"wbnats/internal/lib/pii"
+	orderService "wbnats/internal/services/order"
+)
+
+const DateLayout = "2006-01-02T15:04:05Z"
+
+const (
+	RuleRequired = "required"
+	RuleFormat   = "format"
+	RuleRange    = "range"
+	RuleMismatch = "mismatch"
+)
+
+var (
+	phoneRegexp  = regexp.MustCompile(`^\+[0-9]{10,15}$`)
+	localeRegexp = regexp.MustCompile(`^[a-z]{2}(-[A-Z]{2})?$`)
+)
+
+// currencies is the subset of ISO 4217 codes accepted from producers.
+var currencies = map[string]struct{}{
+	"AMD": {}, "AZN": {}, "BYN": {}, "CNY": {}, "EUR": {}, "GBP": {}, "GEL": {},
+	"ILS": {}, "KGS": {}, "KZT": {}, "RUB": {}, "TJS": {}, "TRY": {}, "UAH": {},
+	"USD": {}, "UZS": {},
+}
+
+type Violation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+type Violations []Violation
+
+func (v Violations) Error() string {
+	msgs := make([]string, 0, len(v))
+	for _, violation := range v {
+		msgs = append(msgs, violation.Field+": "+violation.Message)
+	}
+
+	return "order validation failed: " + strings.Join(msgs, "; ")
+}
+
+type validator struct {
+	violations Violations
+}
+
+func (v *validator) add(field, rule, format string, args ...any) {
+	v.violations = append(v.violations, Violation{
+		Field:   field,
+		Rule:    rule,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) required(field, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, RuleRequired, "must not be empty")
+		return false
+	}
+	return true
+}
+
+// requiredIfSet checks a field of an update, where nil means unchanged.
+func (v *validator) requiredIfSet(field string, value *string) {
+	if value != nil {
+		v.required(field, *value)
+	}
+}
+
+func (v *validator) positive(field string, value int64) {
+	if value <= 0 {
+		v.add(field, RuleRange, "must be greater than 0, got %d", value)
+	}
+}
+
+func (v *validator) nonNegative(field string, value int64) {
+	if value < 0 {
+		v.add(field, RuleRange, "must not be negative, got %d", value)
+	}
+}
+
+// uid checks the order UID has the format the order API accepts, so every
+// saved order can be read back.
+func (v *validator) uid(field, value string) {
+	if v.required(field, value) && !orderService.ValidUID(value) {
+		v.add(field, RuleFormat, "must be 1-200 letters, digits, '_' or '-', got %q", value)
+	}
+}
+
+func (v *validator) locale(field, value string) {
+	if v.required(field, value) && !localeRegexp.MatchString(value) {
+		v.add(field, RuleFormat, "must be an ISO 639-1 language code, got %q", value)
+	}
+}
+
+// Violations end up in logs and dead letters, so personal values are echoed
+// masked only.
+func (v *validator) phone(field, value string) {
+	if v.required(field, value) && !phoneRegexp.MatchString(value) {
+		v.add(field, RuleFormat, "must be in international format, got %q", pii.Phone(value))
+	}
+}
+
+func (v *validator) email(field, value string) {
+	if v.required(field, value) {
+		if addr, err := mail.ParseAddress(value); err != nil || addr.Address != value {
+			v.add(field, RuleFormat, "must be a valid email address, got %q", pii.Email(value))
+		}
+	}
+}
+
+func (v *validator) currency(field, value string) {
+	if v.required(field, value) {
+		if _, ok := currencies[value]; !ok {
+			v.add(field, RuleFormat, "must be a supported ISO 4217 code, got %q", value)
+		}
+	}
+}
+
+// Validate checks the order received from NATS Streaming and returns every
+// violation found. A nil result means the order can be saved.
+func Validate(order *orderNatsStreaming.Order) Violations {
+	v := &validator{}
+
+	v.uid("order_uid", order.UID)
+	v.required("track_number", order.TrackNumber)
+	v.required("entry", order.Entry)
+	v.required("customer_id", order.CustomerID)
+	v.required("delivery_service", order.DeliveryService)
+
+	v.locale("locale", order.Locale)
+
+	if v.required("date_created", order.DateCreated) {
+		if _, err := time.Parse(DateLayout, order.DateCreated); err != nil {
+			v.add("date_created", RuleFormat, "must match %s, got %q", DateLayout, order.DateCreated)
+		}
+	}
+
+	if order.SmID < 0 {
+		v.add("sm_id", RuleRange, "must not be negative, got %d", order.SmID)
+	}
+
+	validateDelivery(v, &order.Delivery)
+	validatePayment(v, &order.Payment)
+	validateItems(v, order)
+
+	return v.violations
+}
+
+func validateDelivery(v *validator, delivery *orderNatsStreaming.Delivery) {
+	v.required("delivery.name", delivery.Name)
+	v.required("delivery.city", delivery.City)
+	v.required("delivery.address", delivery.Address)
+
+	v.phone("delivery.phone", delivery.Phone)
+	v.email("delivery.email", delivery.Email)
+}
+
+func validatePayment(v *validator, payment *orderNatsStreaming.Payment) {
+	v.required("payment.transaction", payment.Transaction)
+	v.required("payment.provider", payment.Provider)
+
+	v.currency("payment.currency", payment.Currency)
+
+	v.positive("payment.amount", payment.Amount)
+	v.positive("payment.payment_dt", payment.PaymentDT)
+	v.nonNegative("payment.delivery_cost", payment.DeliveryCost)
+	v.nonNegative("payment.goods_total", payment.GoodsTotal)
+	v.nonNegative("payment.custom_fee", payment.CustomFee)
+
+	if expected := payment.GoodsTotal + payment.DeliveryCost + payment.CustomFee; payment.Amount != expected {
+		v.add("payment.amount", RuleMismatch,
+			"must equal goods_total + delivery_cost + custom_fee (%d), got %d", expected, payment.Amount)
+	}
+}
+
+func validateItems(v *validator, order *orderNatsStreaming.Order) {
+	if len(order.Items) == 0 {
+		v.add("items", RuleRequired, "must contain at least one item")
+		return
+	}
+
+	var goodsTotal int64
+	for i, item := range order.Items {
+		field := fmt.Sprintf("items[%d]", i)
+
+		validateItem(v, field, &item)
+
+		if item.TrackNumber != order.TrackNumber {
+			v.add(field+".track_number", RuleMismatch,
+				"must equal order track_number %q, got %q", order.TrackNumber, item.TrackNumber)
+		}
+
+		goodsTotal += item.TotalPrice
+	}
+
+	if goodsTotal != order.Payment.GoodsTotal {
+		v.add("payment.goods_total", RuleMismatch,
+			"must equal the sum of items total_price (%d), got %d", goodsTotal, order.Payment.GoodsTotal)
+	}
+}
+
+func validateItem(v *validator, field string, item *orderNatsStreaming.Item) {
+	v.positive(field+".chrt_id", item.ChrtID)
+	v.positive(field+".nm_id", item.NmID)
+	v.required(field+".rid", item.RID)
+	v.required(field+".name", item.Name)
+	v.nonNegative(field+".price", item.Price)
+	v.nonNegative(field+".total_price", item.TotalPrice)
+
+	if item.Sale < 0 || item.Sale > 100 {
+		v.add(field+".sale", RuleRange, "must be between 0 and 100, got %d", item.Sale)
+	}
+
+	if item.TotalPrice > item.Price {
+		v.add(field+".total_price", RuleMismatch,
+			"must not exceed price (%d), got %d", item.Price, item.TotalPrice)
+	}
+}
+
+// ValidateUpdate checks the fields present in an update message. Checks
+// spanning several fields need the stored order and are left to the service.
+func ValidateUpdate(update *orderNatsStreaming.OrderUpdate) Violations {
+	v := &validator{}
+
+	v.uid("order_uid", update.UID)
+	v.positive("version", update.Version)
+
+	v.requiredIfSet("track_number", update.TrackNumber)
+	v.requiredIfSet("entry", update.Entry)
+	v.requiredIfSet("customer_id", update.CustomerID)
+	v.requiredIfSet("delivery_service", update.DeliveryService)
+	if update.Locale != nil {
+		v.locale("locale", *update.Locale)
+	}
+	if update.SmID != nil && *update.SmID < 0 {
+		v.add("sm_id", RuleRange, "must not be negative, got %d", *update.SmID)
+	}
+
+	if d := update.Delivery; d != nil {
+		v.requiredIfSet("delivery.name", d.Name)
+		v.requiredIfSet("delivery.city", d.City)
+		v.requiredIfSet("delivery.address", d.Address)
+		if d.Phone != nil {
+			v.phone("delivery.phone", *d.Phone)
+		}
+		if d.Email != nil {
+			v.email("delivery.email", *d.Email)
+		}
+	}
+
+	if p := update.Payment; p != nil {
+		v.requiredIfSet("payment.transaction", p.Transaction)
+		v.requiredIfSet("payment.provider", p.Provider)
+		if p.Currency != nil {
+			v.currency("payment.currency", *p.Currency)
+		}
+		if p.Amount != nil {
+			v.positive("payment.amount", *p.Amount)
+		}
+		if p.PaymentDT != nil {
+			v.positive("payment.payment_dt", *p.PaymentDT)
+		}
+		if p.DeliveryCost != nil {
+			v.nonNegative("payment.delivery_cost", *p.DeliveryCost)
+		}
+		if p.GoodsTotal != nil {
+			v.nonNegative("payment.goods_total", *p.GoodsTotal)
+		}
+		if p.CustomFee != nil {
+			v.nonNegative("payment.custom_fee", *p.CustomFee)
+		}
+	}
+
+	if update.Items != nil {
+		if len(update.Items) == 0 {
+			v.add("items", RuleRequired, "must contain at least one item")
+		}
+		for i := range update.Items {
+			validateItem(v, fmt.Sprintf("items[%d]", i), &update.Items[i])
+		}
+	}
+
+	return v.violations
+}
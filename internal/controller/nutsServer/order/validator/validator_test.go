@@ -0,0 +1,336 @@
+package orderValidator
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	orderNatsStreaming "wbnats/internal/controller/nutsServer/order/models"
+)
+
+func validOrder() *orderNatsStreaming.Order {
+	return &orderNatsStreaming.Order{
+		UID:         "b563feb7b2b84b6test",
+		TrackNumber: "WBILMTESTTRACK",
+		Entry:       "WBIL",
+		Delivery: orderNatsStreaming.Delivery{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			Zip:     "2639809",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Region:  "Kraiot",
+			Email:   "test@gmail.com",
+		},
+		Payment: orderNatsStreaming.Payment{
+			Transaction:  "b563feb7b2b84b6test",
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       1817,
+			PaymentDT:    1637907727,
+			Bank:         "alpha",
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+			CustomFee:    0,
+		},
+		Items: []orderNatsStreaming.Item{{
+			ChrtID:      9934930,
+			TrackNumber: "WBILMTESTTRACK",
+			Price:       453,
+			RID:         "ab4219087a764ae0btest",
+			Name:        "Mascaras",
+			Sale:        30,
+			Size:        "0",
+			TotalPrice:  317,
+			NmID:        2389212,
+			Brand:       "Vivienne Sabo",
+			Status:      202,
+		}},
+		Locale:          "en",
+		CustomerID:      "test",
+		DeliveryService: "meest",
+		Shardkey:        "9",
+		SmID:            99,
+		DateCreated:     "2021-11-26T06:22:19Z",
+		OofShard:        "1",
+	}
+}
+
+// fieldRules flattens violations for comparison, ignoring messages.
+func fieldRules(violations Violations) []string {
+	out := make([]string, 0, len(violations))
+	for _, v := range violations {
+		out = append(out, v.Field+":"+v.Rule)
+	}
+	return out
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(o *orderNatsStreaming.Order)
+		want   []string
+	}{
+		{
+			name:   "valid order",
+			modify: func(o *orderNatsStreaming.Order) {},
+		},
+		{
+			name:   "missing uid",
+			modify: func(o *orderNatsStreaming.Order) { o.UID = " " },
+			want:   []string{"order_uid:required"},
+		},
+		{
+			name:   "uid not readable through the API",
+			modify: func(o *orderNatsStreaming.Order) { o.UID = "order/1" },
+			want:   []string{"order_uid:format"},
+		},
+		{
+			name:   "uid too long",
+			modify: func(o *orderNatsStreaming.Order) { o.UID = strings.Repeat("a", 201) },
+			want:   []string{"order_uid:format"},
+		},
+		{
+			name: "missing required strings",
+			modify: func(o *orderNatsStreaming.Order) {
+				o.Entry, o.CustomerID, o.DeliveryService = "", "", ""
+			},
+			want: []string{"entry:required", "customer_id:required", "delivery_service:required"},
+		},
+		{
+			name:   "locale format",
+			modify: func(o *orderNatsStreaming.Order) { o.Locale = "english" },
+			want:   []string{"locale:format"},
+		},
+		{
+			name:   "locale with region",
+			modify: func(o *orderNatsStreaming.Order) { o.Locale = "en-US" },
+		},
+		{
+			name:   "date format",
+			modify: func(o *orderNatsStreaming.Order) { o.DateCreated = "2021-11-26 06:22:19" },
+			want:   []string{"date_created:format"},
+		},
+		{
+			name:   "negative sm_id",
+			modify: func(o *orderNatsStreaming.Order) { o.SmID = -1 },
+			want:   []string{"sm_id:range"},
+		},
+		{
+			name:   "phone without country code",
+			modify: func(o *orderNatsStreaming.Order) { o.Delivery.Phone = "89720000000" },
+			want:   []string{"delivery.phone:format"},
+		},
+		{
+			name:   "phone too short",
+			modify: func(o *orderNatsStreaming.Order) { o.Delivery.Phone = "+7123" },
+			want:   []string{"delivery.phone:format"},
+		},
+		{
+			name:   "email format",
+			modify: func(o *orderNatsStreaming.Order) { o.Delivery.Email = "test.gmail.com" },
+			want:   []string{"delivery.email:format"},
+		},
+		{
+			name:   "email with display name",
+			modify: func(o *orderNatsStreaming.Order) { o.Delivery.Email = "Test <test@gmail.com>" },
+			want:   []string{"delivery.email:format"},
+		},
+		{
+			name:   "missing delivery name",
+			modify: func(o *orderNatsStreaming.Order) { o.Delivery.Name = "" },
+			want:   []string{"delivery.name:required"},
+		},
+		{
+			name:   "unsupported currency",
+			modify: func(o *orderNatsStreaming.Order) { o.Payment.Currency = "usd" },
+			want:   []string{"payment.currency:format"},
+		},
+		{
+			name: "amount does not add up",
+			modify: func(o *orderNatsStreaming.Order) {
+				o.Payment.Amount = 1000
+			},
+			want: []string{"payment.amount:mismatch"},
+		},
+		{
+			name: "non-positive amount",
+			modify: func(o *orderNatsStreaming.Order) {
+				o.Payment.Amount, o.Payment.DeliveryCost, o.Payment.GoodsTotal = 0, 0, 0
+				o.Items[0].Price, o.Items[0].TotalPrice = 0, 0
+			},
+			want: []string{"payment.amount:range"},
+		},
+		{
+			name: "negative delivery cost",
+			modify: func(o *orderNatsStreaming.Order) {
+				o.Payment.DeliveryCost = -1
+				o.Payment.Amount = o.Payment.GoodsTotal - 1
+			},
+			want: []string{"payment.delivery_cost:range"},
+		},
+		{
+			name:   "no items",
+			modify: func(o *orderNatsStreaming.Order) { o.Items = nil },
+			want:   []string{"items:required"},
+		},
+		{
+			name:   "item track number differs",
+			modify: func(o *orderNatsStreaming.Order) { o.Items[0].TrackNumber = "OTHER" },
+			want:   []string{"items[0].track_number:mismatch"},
+		},
+		{
+			name:   "item sale out of range",
+			modify: func(o *orderNatsStreaming.Order) { o.Items[0].Sale = 101 },
+			want:   []string{"items[0].sale:range"},
+		},
+		{
+			name: "item total exceeds price",
+			modify: func(o *orderNatsStreaming.Order) {
+				o.Items[0].Price = 300
+			},
+			want: []string{"items[0].total_price:mismatch"},
+		},
+		{
+			name: "goods total differs from items",
+			modify: func(o *orderNatsStreaming.Order) {
+				o.Payment.GoodsTotal = 300
+				o.Payment.Amount = 1800
+			},
+			want: []string{"payment.goods_total:mismatch"},
+		},
+		{
+			name: "item ids and names",
+			modify: func(o *orderNatsStreaming.Order) {
+				o.Items[0].ChrtID, o.Items[0].NmID = 0, -1
+				o.Items[0].RID, o.Items[0].Name = "", ""
+			},
+			want: []string{"items[0].chrt_id:range", "items[0].nm_id:range", "items[0].rid:required", "items[0].name:required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := validOrder()
+			tt.modify(order)
+
+			got := fieldRules(Validate(order))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryViolation(t *testing.T) {
+	order := validOrder()
+	order.UID = ""
+	order.Delivery.Phone = "123"
+	order.Payment.Currency = "XXX"
+
+	violations := Validate(order)
+
+	want := []string{"order_uid:required", "delivery.phone:format", "payment.currency:format"}
+	if got := fieldRules(violations); !slices.Equal(got, want) {
+		t.Fatalf("Validate() = %v, want %v", got, want)
+	}
+
+	wantErr := `order validation failed: order_uid: must not be empty; ` +
+		`delivery.phone: must be in international format, got "***"; ` +
+		`payment.currency: must be a supported ISO 4217 code, got "XXX"`
+	if got := violations.Error(); got != wantErr {
+		t.Errorf("Error() = %q, want %q", got, wantErr)
+	}
+}
+
+func TestValidateMasksPersonalData(t *testing.T) {
+	order := validOrder()
+	order.Delivery.Phone = "79720001234"
+	order.Delivery.Email = "secret.gmail.com"
+
+	err := Validate(order).Error()
+	for _, value := range []string{"79720001234", "secret.gmail.com"} {
+		if strings.Contains(err, value) {
+			t.Errorf("violations leak %q: %s", value, err)
+		}
+	}
+}
+
+func TestValidateUpdate(t *testing.T) {
+	str := func(s string) *string { return &s }
+	num := func(n int64) *int64 { return &n }
+
+	tests := []struct {
+		name   string
+		update orderNatsStreaming.OrderUpdate
+		want   []string
+	}{
+		{
+			name:   "only identity",
+			update: orderNatsStreaming.OrderUpdate{UID: "b563feb7b2b84b6test", Version: 1},
+		},
+		{
+			name:   "missing identity",
+			update: orderNatsStreaming.OrderUpdate{},
+			want:   []string{"order_uid:required", "version:range"},
+		},
+		{
+			name:   "malformed uid",
+			update: orderNatsStreaming.OrderUpdate{UID: "a b", Version: 1},
+			want:   []string{"order_uid:format"},
+		},
+		{
+			name: "fields set to empty",
+			update: orderNatsStreaming.OrderUpdate{
+				UID: "b563feb7b2b84b6test", Version: 2,
+				TrackNumber: str(""),
+				Delivery:    &orderNatsStreaming.DeliveryUpdate{City: str(" ")},
+				Payment:     &orderNatsStreaming.PaymentUpdate{Provider: str("")},
+			},
+			want: []string{"track_number:required", "delivery.city:required", "payment.provider:required"},
+		},
+		{
+			name: "formats",
+			update: orderNatsStreaming.OrderUpdate{
+				UID: "b563feb7b2b84b6test", Version: 2,
+				Locale:   str("EN"),
+				Delivery: &orderNatsStreaming.DeliveryUpdate{Phone: str("123"), Email: str("test")},
+				Payment:  &orderNatsStreaming.PaymentUpdate{Currency: str("ABC")},
+			},
+			want: []string{"locale:format", "delivery.phone:format", "delivery.email:format", "payment.currency:format"},
+		},
+		{
+			name: "ranges",
+			update: orderNatsStreaming.OrderUpdate{
+				UID: "b563feb7b2b84b6test", Version: 2,
+				SmID:    num(-1),
+				Payment: &orderNatsStreaming.PaymentUpdate{Amount: num(0), CustomFee: num(-5)},
+			},
+			want: []string{"sm_id:range", "payment.amount:range", "payment.custom_fee:range"},
+		},
+		{
+			name: "empty items",
+			update: orderNatsStreaming.OrderUpdate{
+				UID: "b563feb7b2b84b6test", Version: 2,
+				Items: []orderNatsStreaming.Item{},
+			},
+			want: []string{"items:required"},
+		},
+		{
+			name: "invalid item",
+			update: orderNatsStreaming.OrderUpdate{
+				UID: "b563feb7b2b84b6test", Version: 2,
+				Items: []orderNatsStreaming.Item{{ChrtID: 1, NmID: 1, RID: "r", Name: "n", Price: 10, TotalPrice: 20}},
+			},
+			want: []string{"items[0].total_price:mismatch"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fieldRules(ValidateUpdate(&tt.update))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ValidateUpdate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
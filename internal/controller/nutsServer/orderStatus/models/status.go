@@ -0,0 +1,8 @@
+package orderStatusNatsStreaming
+
+type StatusChange struct {
+	OrderUID  string `json:"order_uid"`
+	Status    string `json:"status"`
+	ChangedAt string `json:"changed_at"`
+	Reason    string `json:"reason"`
+}
@@ -0,0 +1,92 @@
+package orderStatusNatsStreaming
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	orderValidator "wbnats/internal/controller/nutsServer/order/validator"
+	orderStatusNatsStreaming "wbnats/internal/controller/nutsServer/orderStatus/models"
+	settleNatsStreaming "wbnats/internal/controller/nutsServer/settle"
+	"wbnats/internal/lib/logger/sl"
+	"wbnats/internal/lib/message"
+	"wbnats/internal/lib/metrics"
+	orderService "wbnats/internal/services/order"
+	"wbnats/internal/services/order/models"
+)
+
+// maxNotFoundDeliveries bounds waiting for an order whose creation message
+// hasn't been processed yet.
+const maxNotFoundDeliveries = 5
+
+type StatusChanger interface {
+	ChangeStatus(ctx context.Context, change *models.StatusChange) (models.SaveResult, error)
+}
+
+type DeadLetterSender interface {
+	Send(ctx context.Context, deadLetter *models.DeadLetter) error
+}
+
+// NewStatusChangeHandler applies order status change events. Events breaking
+// the allowed transitions are dead-lettered.
+func NewStatusChangeHandler(log *slog.Logger, changer StatusChanger, deadLetters DeadLetterSender) func(message.Message) {
+	return func(m message.Message) {
+		settler := settleNatsStreaming.New(log, m, deadLetters, settleNatsStreaming.Hooks{
+			Retried: metrics.StatusChanges.WithLabelValues("retried").Inc,
+			Rejected: func(string) {
+				metrics.StatusChanges.WithLabelValues("rejected").Inc()
+			},
+		})
+
+		event := orderStatusNatsStreaming.StatusChange{}
+		if err := json.Unmarshal(m.Payload(), &event); err != nil {
+			log.Error("failed to deserialization status change", sl.Err(err))
+			settler.Reject(models.DeadLetterReasonUnmarshal, err)
+			return
+		}
+
+		changedAt, err := time.Parse(orderValidator.DateLayout, event.ChangedAt)
+		if err != nil {
+			err = fmt.Errorf("changed_at must match %s, got %q", orderValidator.DateLayout, event.ChangedAt)
+			log.Error("status change rejected by validation", slog.String("orderUID", event.OrderUID), sl.Err(err))
+			settler.Reject(models.DeadLetterReasonValidation, err)
+			return
+		}
+
+		result, err := changer.ChangeStatus(context.Background(), &models.StatusChange{
+			OrderUID:  event.OrderUID,
+			Status:    models.Status(event.Status),
+			ChangedAt: changedAt,
+			Reason:    event.Reason,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, orderService.ErrUnavailable):
+				settler.Retry(models.DeadLetterReasonSave, err)
+			case errors.Is(err, orderService.ErrNotFound):
+				log.Warn("status change for unknown order", slog.String("orderUID", event.OrderUID))
+				settler.RetryUpTo(maxNotFoundDeliveries, models.DeadLetterReasonNotFound, err)
+			case errors.Is(err, orderService.ErrInvalidTransition):
+				log.Error("status change rejected", slog.String("orderUID", event.OrderUID), sl.Err(err))
+				settler.Reject(models.DeadLetterReasonTransition, err)
+			case errors.Is(err, orderService.ErrInvalidID), errors.Is(err, orderService.ErrInvalidStatus):
+				log.Error("status change rejected by validation", slog.String("orderUID", event.OrderUID), sl.Err(err))
+				settler.Reject(models.DeadLetterReasonValidation, err)
+			default:
+				log.Error("failed to change order status", sl.Err(err))
+				settler.Reject(models.DeadLetterReasonSave, err)
+			}
+			return
+		}
+
+		metrics.StatusChanges.WithLabelValues(result.String()).Inc()
+		log.Info("order status change processed",
+			slog.String("orderUID", event.OrderUID),
+			slog.String("status", event.Status),
+			slog.String("result", result.String()),
+		)
+		settler.Ack()
+	}
+}
@@ -0,0 +1,163 @@
+package orderStatusNatsStreaming
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+	"wbnats/internal/lib/message"
+	orderService "wbnats/internal/services/order"
+	"wbnats/internal/services/order/models"
+)
+
+type fakeChanger struct {
+	err     error
+	changed *models.StatusChange
+}
+
+func (f *fakeChanger) ChangeStatus(_ context.Context, change *models.StatusChange) (models.SaveResult, error) {
+	f.changed = change
+	return models.SaveResultInserted, f.err
+}
+
+type fakeDeadLetters struct {
+	sent []*models.DeadLetter
+}
+
+func (f *fakeDeadLetters) Send(_ context.Context, deadLetter *models.DeadLetter) error {
+	f.sent = append(f.sent, deadLetter)
+	return nil
+}
+
+func TestStatusChangeHandler(t *testing.T) {
+	const event = `{"order_uid":"b563feb7b2b84b6test","status":"paid","changed_at":"2021-11-26T07:00:00Z"}`
+	notFound := fmt.Errorf("change status: %w", orderService.ErrNotFound)
+
+	tests := []struct {
+		name          string
+		payload       string
+		redeliveries  int
+		maxDeliveries int
+		err           error
+		wantAcked     bool
+		wantNakDelay  time.Duration
+		wantReason    string
+	}{
+		{
+			name:      "applied",
+			payload:   event,
+			wantAcked: true,
+		},
+		{
+			name:       "malformed json",
+			payload:    `not json`,
+			wantAcked:  true,
+			wantReason: models.DeadLetterReasonUnmarshal,
+		},
+		{
+			name:       "malformed date",
+			payload:    `{"order_uid":"b563feb7b2b84b6test","status":"paid","changed_at":"26.11.2021"}`,
+			wantAcked:  true,
+			wantReason: models.DeadLetterReasonValidation,
+		},
+		{
+			name:       "transition not allowed",
+			payload:    event,
+			err:        fmt.Errorf("change status: %w", orderService.ErrInvalidTransition),
+			wantAcked:  true,
+			wantReason: models.DeadLetterReasonTransition,
+		},
+		{
+			name:       "unknown status",
+			payload:    event,
+			err:        fmt.Errorf("change status: %w", orderService.ErrInvalidStatus),
+			wantAcked:  true,
+			wantReason: models.DeadLetterReasonValidation,
+		},
+		{
+			name:       "permanent failure",
+			payload:    event,
+			err:        errors.New("boom"),
+			wantAcked:  true,
+			wantReason: models.DeadLetterReasonSave,
+		},
+		{
+			name:         "storage unavailable",
+			payload:      event,
+			redeliveries: 2,
+			err:          fmt.Errorf("change status: %w", orderService.ErrUnavailable),
+			wantNakDelay: 4 * time.Second,
+		},
+		{
+			name:         "order not created yet",
+			payload:      event,
+			err:          notFound,
+			wantNakDelay: time.Second,
+		},
+		{
+			name:          "order still missing on the last delivery",
+			payload:       event,
+			redeliveries:  1,
+			maxDeliveries: 2,
+			err:           notFound,
+			wantAcked:     true,
+			wantReason:    models.DeadLetterReasonNotFound,
+		},
+		{
+			name:          "order still missing after waiting long enough",
+			payload:       event,
+			redeliveries:  maxNotFoundDeliveries - 1,
+			maxDeliveries: 10,
+			err:           notFound,
+			wantAcked:     true,
+			wantReason:    models.DeadLetterReasonNotFound,
+		},
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deadLetters := &fakeDeadLetters{}
+			m := message.NewLocal("orders.status", []byte(tt.payload), nil).Redelivered(tt.redeliveries, tt.maxDeliveries)
+
+			NewStatusChangeHandler(log, &fakeChanger{err: tt.err}, deadLetters)(m)
+
+			if got := m.Acked(); got != tt.wantAcked {
+				t.Errorf("acked = %v, want %v", got, tt.wantAcked)
+			}
+			delay, nakked := m.Nakked()
+			if nakked != (tt.wantNakDelay > 0) || delay != tt.wantNakDelay {
+				t.Errorf("nak = %v (%v), want delay %v", nakked, delay, tt.wantNakDelay)
+			}
+
+			var reason string
+			if len(deadLetters.sent) > 0 {
+				reason = deadLetters.sent[0].Reason
+			}
+			if reason != tt.wantReason {
+				t.Errorf("dead letter reason = %q, want %q", reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestStatusChangeHandlerParsesEvent(t *testing.T) {
+	changer := &fakeChanger{}
+	m := message.NewLocal("orders.status", []byte(`{"order_uid":"b563feb7b2b84b6test","status":"shipped","changed_at":"2021-11-26T07:00:00Z","reason":"courier"}`), nil)
+
+	NewStatusChangeHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), changer, &fakeDeadLetters{})(m)
+
+	want := models.StatusChange{
+		OrderUID:  "b563feb7b2b84b6test",
+		Status:    models.Status("shipped"),
+		ChangedAt: time.Date(2021, 11, 26, 7, 0, 0, 0, time.UTC),
+		Reason:    "courier",
+	}
+	if changer.changed == nil || *changer.changed != want {
+		t.Errorf("change = %+v, want %+v", changer.changed, want)
+	}
+}
@@ -0,0 +1,135 @@
+package settleNatsStreaming
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"wbnats/internal/lib/logger/sl"
+	"wbnats/internal/lib/message"
+	"wbnats/internal/services/order/models"
+)
+
+const (
+	retryBaseDelay = time.Second
+	retryMaxDelay  = 30 * time.Second
+)
+
+type DeadLetterSender interface {
+	Send(ctx context.Context, deadLetter *models.DeadLetter) error
+}
+
+// Hooks let handlers count outcomes in their own metrics. Nil hooks are skipped.
+type Hooks struct {
+	Retried  func()
+	Rejected func(reason string)
+}
+
+// Settler ends the handling of a message: it is acknowledged, redelivered or
+// dead-lettered. A message is never left to be dropped by its source.
+type Settler struct {
+	log         *slog.Logger
+	m           message.Message
+	deadLetters DeadLetterSender
+	hooks       Hooks
+}
+
+func New(log *slog.Logger, m message.Message, deadLetters DeadLetterSender, hooks Hooks) *Settler {
+	return &Settler{
+		log:         log.With(slog.String("subject", m.Subject())),
+		m:           m,
+		deadLetters: deadLetters,
+		hooks:       hooks,
+	}
+}
+
+func (s *Settler) Ack() {
+	if err := s.m.Ack(); err != nil {
+		s.log.Error("failed to acknowledge message", sl.Err(err))
+	}
+}
+
+// Reject handles permanent failures: the message is acknowledged only once it
+// has been dead-lettered. Otherwise it is negatively acknowledged so the
+// dead-lettering is tried again on redelivery; past its last JetStream delivery
+// it stays unacknowledged in the stream and the server reports it in its
+// max-deliveries advisory.
+func (s *Settler) Reject(reason string, cause error) {
+	if s.hooks.Rejected != nil {
+		s.hooks.Rejected(reason)
+	}
+
+	err := s.deadLetters.Send(context.Background(), &models.DeadLetter{
+		Subject:    s.m.Subject(),
+		Sequence:   s.m.Sequence(),
+		ReceivedAt: s.m.ReceivedAt(),
+		Payload:    s.m.Payload(),
+		Reason:     reason,
+		Error:      cause.Error(),
+		FailedAt:   time.Now(),
+	})
+	if err != nil {
+		delay := RetryDelay(s.m.Redeliveries())
+		s.log.Error("failed to dead-letter message, requesting redelivery",
+			slog.Uint64("sequence", s.m.Sequence()),
+			slog.Int("redeliveries", s.m.Redeliveries()),
+			slog.Duration("delay", delay),
+			sl.Err(err),
+		)
+		if err := s.m.Nak(delay); err != nil {
+			s.log.Error("failed to negatively acknowledge message", sl.Err(err))
+		}
+		return
+	}
+	s.Ack()
+}
+
+// Retry handles transient failures by asking for redelivery with a delay
+// growing with the number of redeliveries. On the last delivery the source
+// allows the message is rejected with reason instead.
+func (s *Settler) Retry(reason string, cause error) {
+	s.RetryUpTo(0, reason, cause)
+}
+
+// RetryUpTo is Retry giving up after the given number of deliveries even if
+// the source would deliver more; 0 leaves the limit to the source.
+func (s *Settler) RetryUpTo(deliveries int, reason string, cause error) {
+	if s.lastDelivery(deliveries) {
+		s.log.Error("giving up on message after its last delivery",
+			slog.Uint64("sequence", s.m.Sequence()),
+			slog.Int("redeliveries", s.m.Redeliveries()),
+			sl.Err(cause),
+		)
+		s.Reject(reason, cause)
+		return
+	}
+
+	if s.hooks.Retried != nil {
+		s.hooks.Retried()
+	}
+
+	delay := RetryDelay(s.m.Redeliveries())
+	s.log.Warn("requesting redelivery",
+		slog.Uint64("sequence", s.m.Sequence()),
+		slog.Int("redeliveries", s.m.Redeliveries()),
+		slog.Duration("delay", delay),
+		sl.Err(cause),
+	)
+	if err := s.m.Nak(delay); err != nil {
+		s.log.Error("failed to negatively acknowledge message", sl.Err(err))
+	}
+}
+
+func (s *Settler) lastDelivery(deliveries int) bool {
+	limit := s.m.MaxDeliveries()
+	if deliveries > 0 && (limit == 0 || deliveries < limit) {
+		limit = deliveries
+	}
+	return limit > 0 && s.m.Redeliveries()+1 >= limit
+}
+
+func RetryDelay(redeliveries int) time.Duration {
+	if redeliveries >= 5 {
+		return retryMaxDelay
+	}
+	return min(retryBaseDelay<<redeliveries, retryMaxDelay)
+}
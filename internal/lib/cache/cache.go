@@ -0,0 +1,246 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	PolicyLRU  = "lru"
+	PolicyLFU  = "lfu"
+	PolicyNone = "none"
+)
+
+type Config struct {
+	Policy     string
+	MaxEntries int
+	MaxBytes   int64
+	TTL        time.Duration
+}
+
+type Stats struct {
+	Hits        uint64 `json:"hits"`
+	Misses      uint64 `json:"misses"`
+	Evictions   uint64 `json:"evictions"`
+	Expirations uint64 `json:"expirations"`
+	Entries     int    `json:"entries"`
+	Bytes       int64  `json:"bytes"`
+}
+
+type Cache[V any] interface {
+	Get(key string) (V, bool)
+	Set(key string, value V)
+	// Add stores value only if key holds no live entry and reports whether it
+	// did. Loaders use it so they never replace a fresher value written
+	// while they were reading.
+	Add(key string, value V) bool
+	Delete(key string)
+	Len() int
+	Stats() Stats
+}
+
+// New builds a cache for the configured policy. sizeOf estimates the memory
+// held by a value and is only used when MaxBytes is set.
+func New[V any](cfg Config, sizeOf func(V) int64) (Cache[V], error) {
+	switch cfg.Policy {
+	case PolicyLRU, "":
+		return newBounded[V](cfg, sizeOf, newLRU[V]()), nil
+	case PolicyLFU:
+		return newBounded[V](cfg, sizeOf, newLFU[V]()), nil
+	case PolicyNone:
+		return &noop[V]{}, nil
+	default:
+		return nil, fmt.Errorf("unknown cache policy %q", cfg.Policy)
+	}
+}
+
+type entry[V any] struct {
+	key       string
+	value     V
+	size      int64
+	expiresAt time.Time
+
+	// bookkeeping owned by the eviction policy
+	ref   any
+	freq  uint64
+	tick  uint64
+	index int
+}
+
+// policy decides which entry goes first when the cache is over its limits.
+type policy[V any] interface {
+	add(e *entry[V])
+	touch(e *entry[V])
+	remove(e *entry[V])
+	victim() *entry[V]
+}
+
+type bounded[V any] struct {
+	mu      sync.Mutex
+	cfg     Config
+	sizeOf  func(V) int64
+	policy  policy[V]
+	entries map[string]*entry[V]
+	stats   Stats
+}
+
+func newBounded[V any](cfg Config, sizeOf func(V) int64, p policy[V]) *bounded[V] {
+	return &bounded[V]{
+		cfg:     cfg,
+		sizeOf:  sizeOf,
+		policy:  p,
+		entries: make(map[string]*entry[V]),
+	}
+}
+
+func (c *bounded[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		var zero V
+		return zero, false
+	}
+
+	if !e.expiresAt.IsZero() && time.Now().After(e.expiresAt) {
+		c.remove(e)
+		c.stats.Expirations++
+		c.stats.Misses++
+		var zero V
+		return zero, false
+	}
+
+	c.policy.touch(e)
+	c.stats.Hits++
+	return e.value, true
+}
+
+func (c *bounded[V]) Set(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(key, value)
+}
+
+func (c *bounded[V]) Add(key string, value V) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		if e.expiresAt.IsZero() || !time.Now().After(e.expiresAt) {
+			return false
+		}
+		c.remove(e)
+		c.stats.Expirations++
+	}
+	c.set(key, value)
+	return true
+}
+
+// set stores value and evicts entries until the cache is within its limits.
+// Callers hold mu.
+func (c *bounded[V]) set(key string, value V) {
+	var size int64
+	if c.cfg.MaxBytes > 0 && c.sizeOf != nil {
+		size = c.sizeOf(value)
+	}
+
+	var expiresAt time.Time
+	if c.cfg.TTL > 0 {
+		expiresAt = time.Now().Add(c.cfg.TTL)
+	}
+
+	if e, ok := c.entries[key]; ok {
+		c.stats.Bytes += size - e.size
+		e.value, e.size, e.expiresAt = value, size, expiresAt
+		c.policy.touch(e)
+	} else {
+		e := &entry[V]{key: key, value: value, size: size, expiresAt: expiresAt}
+		c.entries[key] = e
+		c.stats.Bytes += size
+		// Room is made before the entry joins the policy; otherwise LFU would
+		// evict it straight away as the least used one.
+		for len(c.entries) > 1 && c.overLimit() {
+			c.remove(c.policy.victim())
+			c.stats.Evictions++
+		}
+		c.policy.add(e)
+	}
+
+	for c.overLimit() {
+		c.remove(c.policy.victim())
+		c.stats.Evictions++
+	}
+}
+
+func (c *bounded[V]) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+}
+
+func (c *bounded[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.entries)
+}
+
+func (c *bounded[V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = len(c.entries)
+	return stats
+}
+
+func (c *bounded[V]) overLimit() bool {
+	if len(c.entries) == 0 {
+		return false
+	}
+	return (c.cfg.MaxEntries > 0 && len(c.entries) > c.cfg.MaxEntries) ||
+		(c.cfg.MaxBytes > 0 && c.stats.Bytes > c.cfg.MaxBytes)
+}
+
+func (c *bounded[V]) remove(e *entry[V]) {
+	c.policy.remove(e)
+	delete(c.entries, e.key)
+	c.stats.Bytes -= e.size
+}
+
+// noop never stores anything; it is used when caching is disabled.
+type noop[V any] struct {
+	mu    sync.Mutex
+	stats Stats
+}
+
+func (c *noop[V]) Get(string) (V, bool) {
+	c.mu.Lock()
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	var zero V
+	return zero, false
+}
+
+func (c *noop[V]) Set(string, V) {}
+
+func (c *noop[V]) Add(string, V) bool { return false }
+
+func (c *noop[V]) Delete(string) {}
+
+func (c *noop[V]) Len() int { return 0 }
+
+func (c *noop[V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.stats
+}
@@ -0,0 +1,67 @@
+package cache
+
+import "container/heap"
+
+// lfu evicts the least frequently used entry, the least recently used one
+// among entries with equal frequency.
+type lfu[V any] struct {
+	entries lfuHeap[V]
+	clock   uint64
+}
+
+func newLFU[V any]() *lfu[V] {
+	return &lfu[V]{}
+}
+
+func (p *lfu[V]) add(e *entry[V]) {
+	p.clock++
+	e.freq, e.tick = 1, p.clock
+	heap.Push(&p.entries, e)
+}
+
+func (p *lfu[V]) touch(e *entry[V]) {
+	p.clock++
+	e.freq++
+	e.tick = p.clock
+	heap.Fix(&p.entries, e.index)
+}
+
+func (p *lfu[V]) remove(e *entry[V]) {
+	heap.Remove(&p.entries, e.index)
+}
+
+func (p *lfu[V]) victim() *entry[V] {
+	return p.entries[0]
+}
+
+type lfuHeap[V any] []*entry[V]
+
+func (h lfuHeap[V]) Len() int { return len(h) }
+
+func (h lfuHeap[V]) Less(i, j int) bool {
+	if h[i].freq != h[j].freq {
+		return h[i].freq < h[j].freq
+	}
+	return h[i].tick < h[j].tick
+}
+
+func (h lfuHeap[V]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *lfuHeap[V]) Push(x any) {
+	e := x.(*entry[V])
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *lfuHeap[V]) Pop() any {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return e
+}
@@ -0,0 +1,73 @@
+package cache
+
+import "testing"
+
+func TestLFUEvictsLeastFrequentlyUsed(t *testing.T) {
+	c := newTestCache(t, Config{Policy: PolicyLFU, MaxEntries: 3})
+
+	c.Set("a", "1")
+	c.Set("b", "2")
+	c.Set("c", "3")
+	c.Get("a")
+	c.Get("a")
+	c.Get("c")
+	c.Set("d", "4")
+
+	assertKeys(t, c, []string{"a", "c", "d"}, []string{"b"})
+	if got := c.Stats().Evictions; got != 1 {
+		t.Errorf("Evictions = %d, want 1", got)
+	}
+}
+
+func TestLFUBreaksTiesByRecency(t *testing.T) {
+	c := newTestCache(t, Config{Policy: PolicyLFU, MaxEntries: 2})
+
+	c.Set("a", "1")
+	c.Set("b", "2")
+	c.Get("b")
+	c.Get("a")
+	c.Set("c", "3")
+
+	// a and b were both used twice, b longer ago.
+	assertKeys(t, c, []string{"a", "c"}, []string{"b"})
+}
+
+func TestLFUKeepsEntryJustSet(t *testing.T) {
+	c := newTestCache(t, Config{Policy: PolicyLFU, MaxEntries: 2})
+
+	c.Set("a", "1")
+	c.Get("a")
+	c.Get("a")
+	c.Set("b", "2")
+	c.Set("c", "3")
+
+	// c is used least, but it was just set; b goes instead.
+	assertKeys(t, c, []string{"a", "c"}, []string{"b"})
+}
+
+func TestLFUEvictsByBytes(t *testing.T) {
+	c := newTestCache(t, Config{Policy: PolicyLFU, MaxBytes: 10})
+
+	c.Set("a", "aaaa")
+	c.Get("a")
+	c.Set("b", "bbbb")
+	c.Set("c", "cccc")
+
+	assertKeys(t, c, []string{"a", "c"}, []string{"b"})
+	if got := c.Stats().Bytes; got != 8 {
+		t.Errorf("Bytes = %d, want 8", got)
+	}
+}
+
+func TestLFUDeleteKeepsHeapConsistent(t *testing.T) {
+	c := newTestCache(t, Config{Policy: PolicyLFU, MaxEntries: 2})
+
+	c.Set("a", "1")
+	c.Set("b", "2")
+	c.Get("b")
+	c.Delete("a")
+	c.Set("c", "3")
+	c.Set("d", "4")
+
+	assertKeys(t, c, []string{"b", "d"}, []string{"a", "c"})
+}
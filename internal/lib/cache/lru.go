@@ -0,0 +1,28 @@
+package cache
+
+import "container/list"
+
+// lru evicts the least recently used entry.
+type lru[V any] struct {
+	order *list.List
+}
+
+func newLRU[V any]() *lru[V] {
+	return &lru[V]{order: list.New()}
+}
+
+func (p *lru[V]) add(e *entry[V]) {
+	e.ref = p.order.PushFront(e)
+}
+
+func (p *lru[V]) touch(e *entry[V]) {
+	p.order.MoveToFront(e.ref.(*list.Element))
+}
+
+func (p *lru[V]) remove(e *entry[V]) {
+	p.order.Remove(e.ref.(*list.Element))
+}
+
+func (p *lru[V]) victim() *entry[V] {
+	return p.order.Back().Value.(*entry[V])
+}
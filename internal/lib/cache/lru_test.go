@@ -0,0 +1,167 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestCache(t *testing.T, cfg Config) Cache[string] {
+	t.Helper()
+
+	c, err := New[string](cfg, func(v string) int64 { return int64(len(v)) })
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return c
+}
+
+func assertKeys(t *testing.T, c Cache[string], present, absent []string) {
+	t.Helper()
+
+	for _, key := range present {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("Get(%q) missed, want hit", key)
+		}
+	}
+	for _, key := range absent {
+		if _, ok := c.Get(key); ok {
+			t.Errorf("Get(%q) hit, want miss", key)
+		}
+	}
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newTestCache(t, Config{Policy: PolicyLRU, MaxEntries: 3})
+
+	c.Set("a", "1")
+	c.Set("b", "2")
+	c.Set("c", "3")
+	c.Get("a")
+	c.Set("d", "4")
+
+	assertKeys(t, c, []string{"a", "c", "d"}, []string{"b"})
+	if got := c.Stats().Evictions; got != 1 {
+		t.Errorf("Evictions = %d, want 1", got)
+	}
+}
+
+func TestLRUSetRefreshesRecency(t *testing.T) {
+	c := newTestCache(t, Config{Policy: PolicyLRU, MaxEntries: 2})
+
+	c.Set("a", "1")
+	c.Set("b", "2")
+	c.Set("a", "10")
+	c.Set("c", "3")
+
+	assertKeys(t, c, []string{"a", "c"}, []string{"b"})
+	if v, _ := c.Get("a"); v != "10" {
+		t.Errorf("Get(a) = %q, want %q", v, "10")
+	}
+}
+
+func TestLRUEvictsByBytes(t *testing.T) {
+	c := newTestCache(t, Config{Policy: PolicyLRU, MaxBytes: 10})
+
+	c.Set("a", "aaaa")
+	c.Set("b", "bbbb")
+	c.Set("c", "cccc")
+
+	assertKeys(t, c, []string{"b", "c"}, []string{"a"})
+	if got := c.Stats().Bytes; got != 8 {
+		t.Errorf("Bytes = %d, want 8", got)
+	}
+
+	c.Set("b", "b")
+	if got := c.Stats().Bytes; got != 5 {
+		t.Errorf("Bytes after shrinking b = %d, want 5", got)
+	}
+
+	c.Delete("c")
+	if got := c.Stats().Bytes; got != 1 {
+		t.Errorf("Bytes after delete = %d, want 1", got)
+	}
+}
+
+func TestLRUKeepsValueLargerThanLimitOnlyAlone(t *testing.T) {
+	c := newTestCache(t, Config{Policy: PolicyLRU, MaxBytes: 4})
+
+	c.Set("a", "aa")
+	c.Set("big", "bigger than the limit")
+
+	if got := c.Len(); got != 0 {
+		t.Errorf("Len() = %d, want 0", got)
+	}
+	if got := c.Stats().Bytes; got != 0 {
+		t.Errorf("Bytes = %d, want 0", got)
+	}
+}
+
+func TestTTLExpiresEntries(t *testing.T) {
+	c := newTestCache(t, Config{Policy: PolicyLRU, TTL: 20 * time.Millisecond})
+
+	c.Set("a", "1")
+	assertKeys(t, c, []string{"a"}, nil)
+
+	time.Sleep(40 * time.Millisecond)
+	assertKeys(t, c, nil, []string{"a"})
+
+	stats := c.Stats()
+	if stats.Expirations != 1 || stats.Entries != 0 {
+		t.Errorf("Stats() = %+v, want 1 expiration and no entries", stats)
+	}
+}
+
+func TestAddKeepsLiveEntry(t *testing.T) {
+	c := newTestCache(t, Config{Policy: PolicyLRU, TTL: 20 * time.Millisecond})
+
+	if !c.Add("a", "fresh") {
+		t.Fatal("Add() to empty cache = false, want true")
+	}
+	if c.Add("a", "stale") {
+		t.Error("Add() over live entry = true, want false")
+	}
+	if v, _ := c.Get("a"); v != "fresh" {
+		t.Errorf("Get(a) = %q, want %q", v, "fresh")
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if !c.Add("a", "reloaded") {
+		t.Error("Add() over expired entry = false, want true")
+	}
+	if v, _ := c.Get("a"); v != "reloaded" {
+		t.Errorf("Get(a) = %q, want %q", v, "reloaded")
+	}
+}
+
+func TestStatsCountHitsAndMisses(t *testing.T) {
+	c := newTestCache(t, Config{Policy: PolicyLRU})
+
+	c.Set("a", "1")
+	c.Get("a")
+	c.Get("a")
+	c.Get("b")
+
+	want := Stats{Hits: 2, Misses: 1, Entries: 1}
+	if got := c.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPolicyNoneStoresNothing(t *testing.T) {
+	c := newTestCache(t, Config{Policy: PolicyNone})
+
+	c.Set("a", "1")
+	if c.Add("b", "2") {
+		t.Error("Add() = true, want false")
+	}
+	assertKeys(t, c, nil, []string{"a", "b"})
+	if got := c.Stats().Misses; got != 2 {
+		t.Errorf("Misses = %d, want 2", got)
+	}
+}
+
+func TestUnknownPolicy(t *testing.T) {
+	if _, err := New[string](Config{Policy: "fifo"}, nil); err == nil {
+		t.Error("New() error = nil, want error for unknown policy")
+	}
+}
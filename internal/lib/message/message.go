@@ -0,0 +1,99 @@
+package message
+
+import (
+	"sync"
+	"time"
+)
+
+// Message is a payload delivered by any source: a broker, an HTTP request, a
+// file replay or a test.
+type Message interface {
+	Subject() string
+	Payload() []byte
+	Headers() map[string][]string
+	// Sequence is the position of the message in its source, 0 if unknown.
+	Sequence() uint64
+	ReceivedAt() time.Time
+	// Redeliveries is the number of earlier delivery attempts, 0 on the first.
+	Redeliveries() int
+	// MaxDeliveries is the number of deliveries after which the source drops
+	// the message, 0 if it redelivers until acknowledged.
+	MaxDeliveries() int
+	// Ack confirms the message is handled and must not be delivered again.
+	Ack() error
+	// Nak asks for redelivery after delay. Sources that can't redeliver on
+	// request leave the message unacknowledged instead.
+	Nak(delay time.Duration) error
+}
+
+// Local is a Message for sources without a broker behind them. Ack and Nak
+// only record the outcome, which the caller reads back with Acked and Nakked.
+type Local struct {
+	subject    string
+	payload    []byte
+	headers    map[string][]string
+	receivedAt time.Time
+
+	redeliveries  int
+	maxDeliveries int
+
+	mu       sync.Mutex
+	acked    bool
+	nakked   bool
+	nakDelay time.Duration
+}
+
+func NewLocal(subject string, payload []byte, headers map[string][]string) *Local {
+	return &Local{
+		subject:    subject,
+		payload:    payload,
+		headers:    headers,
+		receivedAt: time.Now(),
+	}
+}
+
+func (m *Local) Subject() string              { return m.subject }
+func (m *Local) Payload() []byte              { return m.payload }
+func (m *Local) Headers() map[string][]string { return m.headers }
+func (m *Local) Sequence() uint64             { return 0 }
+func (m *Local) ReceivedAt() time.Time        { return m.receivedAt }
+func (m *Local) Redeliveries() int            { return m.redeliveries }
+func (m *Local) MaxDeliveries() int           { return m.maxDeliveries }
+
+// Redelivered marks the message as delivered redeliveries times before, out of
+// at most maxDeliveries, as when replaying one a broker gave up on.
+func (m *Local) Redelivered(redeliveries, maxDeliveries int) *Local {
+	m.redeliveries, m.maxDeliveries = redeliveries, maxDeliveries
+	return m
+}
+
+func (m *Local) Ack() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.acked = true
+	return nil
+}
+
+func (m *Local) Nak(delay time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nakked, m.nakDelay = true, delay
+	return nil
+}
+
+func (m *Local) Acked() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.acked
+}
+
+// Nakked reports whether redelivery was requested and with which delay.
+func (m *Local) Nakked() (time.Duration, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.nakDelay, m.nakked
+}
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"wbnats/internal/lib/cache"
+)
+
+// RegisterCache exposes the order cache statistics.
+func RegisterCache(stats func() cache.Stats) {
+	prometheus.MustRegister(&cacheCollector{stats: stats})
+}
+
+// RegisterPool exposes pgx connection pool statistics.
+func RegisterPool(stats func() *pgxpool.Stat) {
+	prometheus.MustRegister(&poolCollector{stats: stats})
+}
+
+var (
+	cacheHits = prometheus.NewDesc(namespace+"_cache_hits_total",
+		"Order cache hits.", nil, nil)
+	cacheMisses = prometheus.NewDesc(namespace+"_cache_misses_total",
+		"Order cache misses.", nil, nil)
+	cacheEvictions = prometheus.NewDesc(namespace+"_cache_evictions_total",
+		"Orders evicted from the cache to stay within its limits.", nil, nil)
+	cacheExpirations = prometheus.NewDesc(namespace+"_cache_expirations_total",
+		"Orders dropped from the cache after their TTL.", nil, nil)
+	cacheEntries = prometheus.NewDesc(namespace+"_cache_entries",
+		"Orders currently cached.", nil, nil)
+	cacheBytes = prometheus.NewDesc(namespace+"_cache_bytes",
+		"Estimated memory held by cached orders.", nil, nil)
+)
+
+type cacheCollector struct {
+	stats func() cache.Stats
+}
+
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cacheHits
+	ch <- cacheMisses
+	ch <- cacheEvictions
+	ch <- cacheExpirations
+	ch <- cacheEntries
+	ch <- cacheBytes
+}
+
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	ch <- prometheus.MustNewConstMetric(cacheHits, prometheus.CounterValue, float64(s.Hits))
+	ch <- prometheus.MustNewConstMetric(cacheMisses, prometheus.CounterValue, float64(s.Misses))
+	ch <- prometheus.MustNewConstMetric(cacheEvictions, prometheus.CounterValue, float64(s.Evictions))
+	ch <- prometheus.MustNewConstMetric(cacheExpirations, prometheus.CounterValue, float64(s.Expirations))
+	ch <- prometheus.MustNewConstMetric(cacheEntries, prometheus.GaugeValue, float64(s.Entries))
+	ch <- prometheus.MustNewConstMetric(cacheBytes, prometheus.GaugeValue, float64(s.Bytes))
+}
+
+var (
+	poolAcquired = prometheus.NewDesc(namespace+"_db_pool_acquired_conns",
+		"Connections currently acquired from the pool.", nil, nil)
+	poolIdle = prometheus.NewDesc(namespace+"_db_pool_idle_conns",
+		"Idle connections in the pool.", nil, nil)
+	poolTotal = prometheus.NewDesc(namespace+"_db_pool_total_conns",
+		"Total connections in the pool.", nil, nil)
+	poolMax = prometheus.NewDesc(namespace+"_db_pool_max_conns",
+		"Maximum size of the pool.", nil, nil)
+	poolAcquires = prometheus.NewDesc(namespace+"_db_pool_acquires_total",
+		"Successful connection acquires.", nil, nil)
+	poolEmptyAcquires = prometheus.NewDesc(namespace+"_db_pool_empty_acquires_total",
+		"Acquires that had to wait for a connection.", nil, nil)
+	poolAcquireDuration = prometheus.NewDesc(namespace+"_db_pool_acquire_duration_seconds_total",
+		"Total time spent acquiring connections.", nil, nil)
+)
+
+type poolCollector struct {
+	stats func() *pgxpool.Stat
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolAcquired
+	ch <- poolIdle
+	ch <- poolTotal
+	ch <- poolMax
+	ch <- poolAcquires
+	ch <- poolEmptyAcquires
+	ch <- poolAcquireDuration
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	ch <- prometheus.MustNewConstMetric(poolAcquired, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(poolIdle, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(poolTotal, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(poolMax, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(poolAcquires, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolEmptyAcquires, prometheus.CounterValue, float64(s.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolAcquireDuration, prometheus.CounterValue, s.AcquireDuration().Seconds())
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "wbnats"
+
+var (
+	OrdersReceived = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_received_total",
+		Help:      "Order messages received from the broker.",
+	})
+	OrdersValidated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_validated_total",
+		Help:      "Order messages that passed validation.",
+	})
+	OrdersRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_rejected_total",
+		Help:      "Order messages rejected permanently, by reason.",
+	}, []string{"reason"})
+	OrdersSaved = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_saved_total",
+		Help:      "Orders inserted into storage.",
+	})
+	OrdersDuplicate = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_duplicate_total",
+		Help:      "Re-delivered orders identical to the stored ones.",
+	})
+	OrdersRetried = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_retried_total",
+		Help:      "Order messages left for redelivery after a retryable failure.",
+	})
+	StatusChanges = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "order_status_changes_total",
+		Help:      "Order status change events by outcome.",
+	}, []string{"result"})
+	OrderUpdates = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "order_updates_total",
+		Help:      "Order update messages by outcome.",
+	}, []string{"result"})
+
+	StorageDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "storage_operation_duration_seconds",
+		Help:      "Latency of order storage operations.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "status"})
+
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route and status code.",
+	}, []string{"method", "route", "code"})
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+)
+
+func Status(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
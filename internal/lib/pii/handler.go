@@ -0,0 +1,87 @@
+package pii
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// Handler masks personal data in log attributes before passing records on.
+// Attributes are recognised by key: phone, email, address and zip anywhere,
+// name only inside a delivery group or as delivery.name.
+type Handler struct {
+	next   slog.Handler
+	policy Policy
+	// inDelivery is set once the handler is inside a delivery group.
+	inDelivery bool
+}
+
+func NewHandler(next slog.Handler, policy Policy) *Handler {
+	return &Handler{next: next, policy: policy}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	masked := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		masked.AddAttrs(h.mask(a, h.inDelivery))
+		return true
+	})
+	return h.next.Handle(ctx, masked)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	masked := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		masked = append(masked, h.mask(a, h.inDelivery))
+	}
+	return &Handler{next: h.next.WithAttrs(masked), policy: h.policy, inDelivery: h.inDelivery}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{
+		next:       h.next.WithGroup(name),
+		policy:     h.policy,
+		inDelivery: h.inDelivery || strings.ToLower(name) == "delivery",
+	}
+}
+
+func (h *Handler) mask(a slog.Attr, inDelivery bool) slog.Attr {
+	a.Value = a.Value.Resolve()
+	key := strings.ToLower(a.Key)
+
+	if a.Value.Kind() == slog.KindGroup {
+		group := a.Value.Group()
+		masked := make([]slog.Attr, 0, len(group))
+		for _, ga := range group {
+			masked = append(masked, h.mask(ga, inDelivery || key == "delivery"))
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(masked...)}
+	}
+
+	field, ok := fieldOf(key, inDelivery)
+	if !ok || a.Value.Kind() != slog.KindString {
+		return a
+	}
+	return slog.String(a.Key, h.policy.Mask(field, a.Value.String()))
+}
+
+func fieldOf(key string, inDelivery bool) (Field, bool) {
+	for _, prefix := range []string{"delivery.", "delivery_"} {
+		if rest, ok := strings.CutPrefix(key, prefix); ok {
+			key, inDelivery = rest, true
+		}
+	}
+
+	switch field := Field(key); field {
+	case FieldPhone, FieldEmail, FieldAddress, FieldZip:
+		return field, true
+	case FieldName:
+		return field, inDelivery
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,119 @@
+package pii
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+var testPolicy = Policy{Name: ModePartial, Phone: ModePartial, Email: ModePartial, Address: ModeFull, Zip: ModeNone}
+
+type delivery struct {
+	name, phone string
+}
+
+func (d delivery) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("name", d.name), slog.String("phone", d.phone))
+}
+
+// logged runs log against a JSON logger behind the masking handler and
+// returns the decoded record.
+func logged(t *testing.T, log func(l *slog.Logger)) map[string]any {
+	t.Helper()
+
+	var buf bytes.Buffer
+	log(slog.New(NewHandler(slog.NewJSONHandler(&buf, nil), testPolicy)))
+
+	record := map[string]any{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("decode record %q: %v", buf.String(), err)
+	}
+	return record
+}
+
+func TestHandlerMasksByKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		val  any
+		want any
+	}{
+		{"phone anywhere", "phone", "+79720001234", "+7***1234"},
+		{"key case ignored", "Email", "test@gmail.com", "t***@gmail.com"},
+		{"address", "address", "Ploshad Mira 15", "***"},
+		{"zip left as configured", "zip", "2639809", "2639809"},
+		{"dotted delivery key", "delivery.name", "Test Testov", "T*** T***"},
+		{"underscored delivery key", "delivery_phone", "+79720001234", "+7***1234"},
+		{"name outside delivery", "name", "orders", "orders"},
+		{"unrelated key", "orderUID", "b563feb7b2b84b6test", "b563feb7b2b84b6test"},
+		{"non-string value", "phone", 79720001234, float64(79720001234)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := logged(t, func(l *slog.Logger) { l.Info("msg", tt.key, tt.val) })
+			if got := record[tt.key]; got != tt.want {
+				t.Errorf("%s = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandlerMasksDeliveryGroups(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(l *slog.Logger)
+	}{
+		{"group attribute", func(l *slog.Logger) {
+			l.Info("msg", slog.Group("delivery", slog.String("name", "Test Testov"), slog.String("phone", "+79720001234")))
+		}},
+		{"log valuer", func(l *slog.Logger) {
+			l.Info("msg", slog.Any("delivery", delivery{name: "Test Testov", phone: "+79720001234"}))
+		}},
+		{"logger group", func(l *slog.Logger) {
+			l.WithGroup("delivery").Info("msg", "name", "Test Testov", "phone", "+79720001234")
+		}},
+		{"attrs added inside logger group", func(l *slog.Logger) {
+			l.WithGroup("delivery").With("name", "Test Testov").Info("msg", "phone", "+79720001234")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := logged(t, tt.log)
+
+			group, ok := record["delivery"].(map[string]any)
+			if !ok {
+				t.Fatalf("delivery = %v, want a group", record["delivery"])
+			}
+			if got := group["name"]; got != "T*** T***" {
+				t.Errorf("delivery.name = %v, want T*** T***", got)
+			}
+			if got := group["phone"]; got != "+7***1234" {
+				t.Errorf("delivery.phone = %v, want +7***1234", got)
+			}
+		})
+	}
+}
+
+func TestHandlerLeavesNamesOutsideDeliveryGroups(t *testing.T) {
+	record := logged(t, func(l *slog.Logger) {
+		l.WithGroup("item").Info("msg", "name", "Mascaras")
+	})
+
+	group, _ := record["item"].(map[string]any)
+	if got := group["name"]; got != "Mascaras" {
+		t.Errorf("item.name = %v, want Mascaras", got)
+	}
+}
+
+func TestHandlerMasksWithAttrs(t *testing.T) {
+	record := logged(t, func(l *slog.Logger) {
+		l.With("email", "test@gmail.com").Info("msg")
+	})
+
+	if got := record["email"]; got != "t***@gmail.com" {
+		t.Errorf("email = %v, want t***@gmail.com", got)
+	}
+}
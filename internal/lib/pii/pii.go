@@ -0,0 +1,146 @@
+package pii
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Mode string
+
+const (
+	// ModeNone leaves the value as is.
+	ModeNone Mode = "none"
+	// ModePartial keeps enough of the value to recognise it, e.g. +7***1234.
+	ModePartial Mode = "partial"
+	// ModeFull hides the value completely.
+	ModeFull Mode = "full"
+)
+
+type Field string
+
+const (
+	FieldName    Field = "name"
+	FieldPhone   Field = "phone"
+	FieldEmail   Field = "email"
+	FieldAddress Field = "address"
+	FieldZip     Field = "zip"
+)
+
+const hidden = "***"
+
+// Policy sets how each personal field of a delivery is masked.
+type Policy struct {
+	Name    Mode
+	Phone   Mode
+	Email   Mode
+	Address Mode
+	Zip     Mode
+}
+
+// Unmasked shows every field as is.
+var Unmasked = Policy{Name: ModeNone, Phone: ModeNone, Email: ModeNone, Address: ModeNone, Zip: ModeNone}
+
+func (p Policy) Validate() error {
+	for _, field := range []Field{FieldName, FieldPhone, FieldEmail, FieldAddress, FieldZip} {
+		switch mode := p.mode(field); mode {
+		case ModeNone, ModePartial, ModeFull:
+		default:
+			return fmt.Errorf("unknown masking mode %q for %s", mode, field)
+		}
+	}
+	return nil
+}
+
+func (p Policy) mode(field Field) Mode {
+	switch field {
+	case FieldName:
+		return p.Name
+	case FieldPhone:
+		return p.Phone
+	case FieldEmail:
+		return p.Email
+	case FieldAddress:
+		return p.Address
+	case FieldZip:
+		return p.Zip
+	default:
+		return ModeFull
+	}
+}
+
+// Mask applies the policy of field to value. Empty values stay empty.
+func (p Policy) Mask(field Field, value string) string {
+	if value == "" {
+		return ""
+	}
+
+	switch p.mode(field) {
+	case ModeNone:
+		return value
+	case ModePartial:
+		switch field {
+		case FieldPhone:
+			return Phone(value)
+		case FieldEmail:
+			return Email(value)
+		case FieldName:
+			return Name(value)
+		default:
+			return keep(value, 1, 0)
+		}
+	default:
+		return hidden
+	}
+}
+
+// DeliveryFields points at the personal fields of a delivery, so each
+// representation of a delivery can be masked the same way.
+type DeliveryFields struct {
+	Name    *string
+	Phone   *string
+	Email   *string
+	Address *string
+	Zip     *string
+}
+
+// MaskDelivery masks the fields of d in place.
+func (p Policy) MaskDelivery(d DeliveryFields) {
+	*d.Name = p.Mask(FieldName, *d.Name)
+	*d.Phone = p.Mask(FieldPhone, *d.Phone)
+	*d.Email = p.Mask(FieldEmail, *d.Email)
+	*d.Address = p.Mask(FieldAddress, *d.Address)
+	*d.Zip = p.Mask(FieldZip, *d.Zip)
+}
+
+// Phone keeps the country code and the last four digits: +7***1234.
+func Phone(phone string) string {
+	return keep(phone, 2, 4)
+}
+
+// Email keeps the first letter and the domain: t***@gmail.com.
+func Email(email string) string {
+	at := strings.LastIndexByte(email, '@')
+	if at <= 0 {
+		return hidden
+	}
+	return keep(email[:at], 1, 0) + email[at:]
+}
+
+// Name keeps the first letter of every word: T*** T***.
+func Name(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		words[i] = keep(word, 1, 0)
+	}
+	return strings.Join(words, " ")
+}
+
+// keep leaves head leading and tail trailing runes of s visible, hiding all of
+// it when that would reveal more than half of the value.
+func keep(s string, head, tail int) string {
+	runes := []rune(s)
+	if head+tail > len(runes)/2 {
+		return hidden
+	}
+	return string(runes[:head]) + hidden + string(runes[len(runes)-tail:])
+}
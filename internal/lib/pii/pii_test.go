@@ -0,0 +1,125 @@
+package pii
+
+import "testing"
+
+func TestPhone(t *testing.T) {
+	tests := []struct {
+		phone string
+		want  string
+	}{
+		{"+79720001234", "+7***1234"},
+		{"+997200001234", "+9***1234"},
+		// Six visible characters would reveal more than half.
+		{"+9720000000", "***"},
+		{"+712", "***"},
+		{"", "***"},
+	}
+
+	for _, tt := range tests {
+		if got := Phone(tt.phone); got != tt.want {
+			t.Errorf("Phone(%q) = %q, want %q", tt.phone, got, tt.want)
+		}
+	}
+}
+
+func TestEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{"test@gmail.com", "t***@gmail.com"},
+		{"a.b@c@example.org", "a***@example.org"},
+		{"t@gmail.com", "***@gmail.com"},
+		{"@gmail.com", "***"},
+		{"no-at-sign", "***"},
+	}
+
+	for _, tt := range tests {
+		if got := Email(tt.email); got != tt.want {
+			t.Errorf("Email(%q) = %q, want %q", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Test Testov", "T*** T***"},
+		{"Иван Петров", "И*** П***"},
+		{"Al", "A***"},
+		{"A", "***"},
+		{"  Anna   Maria ", "A*** M***"},
+	}
+
+	for _, tt := range tests {
+		if got := Name(tt.name); got != tt.want {
+			t.Errorf("Name(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPolicyMask(t *testing.T) {
+	policy := Policy{Name: ModePartial, Phone: ModePartial, Email: ModeFull, Address: ModePartial, Zip: ModeNone}
+
+	tests := []struct {
+		field Field
+		value string
+		want  string
+	}{
+		{FieldName, "Test Testov", "T*** T***"},
+		{FieldPhone, "+79720001234", "+7***1234"},
+		{FieldEmail, "test@gmail.com", "***"},
+		{FieldAddress, "Ploshad Mira 15", "P***"},
+		{FieldZip, "2639809", "2639809"},
+		{FieldPhone, "", ""},
+		{Field("city"), "Moscow", "***"},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Mask(tt.field, tt.value); got != tt.want {
+			t.Errorf("Mask(%s, %q) = %q, want %q", tt.field, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestPolicyMaskDelivery(t *testing.T) {
+	policy := Policy{Name: ModePartial, Phone: ModePartial, Email: ModePartial, Address: ModeFull, Zip: ModeNone}
+	name, phone, email, address, zip := "Test Testov", "+79720001234", "test@gmail.com", "Ploshad Mira 15", "2639809"
+
+	policy.MaskDelivery(DeliveryFields{Name: &name, Phone: &phone, Email: &email, Address: &address, Zip: &zip})
+
+	got := []string{name, phone, email, address, zip}
+	want := []string{"T*** T***", "+7***1234", "t***@gmail.com", "***", "2639809"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("MaskDelivery() = %q, want %q", got, want)
+			break
+		}
+	}
+}
+
+func TestUnmaskedKeepsValues(t *testing.T) {
+	for _, field := range []Field{FieldName, FieldPhone, FieldEmail, FieldAddress, FieldZip} {
+		if got := Unmasked.Mask(field, "value@x"); got != "value@x" {
+			t.Errorf("Unmasked.Mask(%s) = %q, want the value as is", field, got)
+		}
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	if err := Unmasked.Validate(); err != nil {
+		t.Errorf("Unmasked.Validate() = %v, want nil", err)
+	}
+
+	policy := Unmasked
+	policy.Email = "hash"
+	if err := policy.Validate(); err == nil {
+		t.Error("Validate() = nil, want error for unknown mode")
+	}
+
+	if err := (Policy{}).Validate(); err == nil {
+		t.Error("Validate() of zero policy = nil, want error")
+	}
+}
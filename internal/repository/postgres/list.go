@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"wbnats/internal/services/order/models"
+)
+
+// ListOrders returns one page of orders matching the filter, ordered by
+// (date_created, order_uid) so the cursor stays stable between pages.
+func (s *Storage) ListOrders(ctx context.Context, filter models.OrderFilter) (models.OrderPage, error) {
+	const op = "repository.postgres.ListOrders"
+
+	var (
+		conds []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.CustomerID != "" {
+		conds = append(conds, "orders.customer_id = "+arg(filter.CustomerID))
+	}
+	if filter.TrackNumber != "" {
+		conds = append(conds, "orders.track_number = "+arg(filter.TrackNumber))
+	}
+	if filter.DeliveryService != "" {
+		conds = append(conds, "orders.delivery_service = "+arg(filter.DeliveryService))
+	}
+	if !filter.CreatedFrom.IsZero() {
+		conds = append(conds, "orders.date_created >= "+arg(filter.CreatedFrom))
+	}
+	if !filter.CreatedTo.IsZero() {
+		conds = append(conds, "orders.date_created < "+arg(filter.CreatedTo))
+	}
+	if filter.Provider != "" {
+		conds = append(conds, "payment.provider = "+arg(filter.Provider))
+	}
+	if filter.Currency != "" {
+		conds = append(conds, "payment.currency = "+arg(filter.Currency))
+	}
+
+	direction, cmp := "DESC", "<"
+	if filter.Sort == models.SortCreatedAsc {
+		direction, cmp = "ASC", ">"
+	}
+
+	if filter.After != nil {
+		conds = append(conds, fmt.Sprintf("(orders.date_created, orders.order_uid) %s (%s, %s)",
+			cmp, arg(filter.After.DateCreated), arg(filter.After.UID)))
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+
+	query := fmt.Sprintf(`SELECT orders.order_uid, transaction, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard, orders.status, orders.version, name, phone, zip, city, address, region, email
+				FROM orders
+				JOIN payment ON orders.order_uid = payment.order_uid
+				JOIN delivery ON orders.order_uid = delivery.order_uid
+				%s
+				ORDER BY orders.date_created %s, orders.order_uid %s
+				LIMIT %s`, where, direction, direction, arg(filter.Limit+1))
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return models.OrderPage{}, wrapErr(op, err)
+	}
+	defer rows.Close()
+
+	orders := []models.Order{}
+	for rows.Next() {
+		order := models.Order{}
+		err := rows.Scan(&order.UID, &order.Payment.Transaction, &order.Payment.RequestID, &order.Payment.Currency, &order.Payment.Provider, &order.Payment.Amount, &order.Payment.PaymentDT, &order.Payment.Bank, &order.Payment.DeliveryCost, &order.Payment.GoodsTotal, &order.Payment.CustomFee, &order.TrackNumber, &order.Entry, &order.Locale, &order.InternalSignature, &order.CustomerID, &order.DeliveryService, &order.Shardkey, &order.SmID, &order.DateCreated, &order.OofShard, &order.Status, &order.Version, &order.Delivery.Name, &order.Delivery.Phone, &order.Delivery.Zip, &order.Delivery.City, &order.Delivery.Address, &order.Delivery.Region, &order.Delivery.Email)
+		if err != nil {
+			return models.OrderPage{}, wrapErr(op, err)
+		}
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return models.OrderPage{}, wrapErr(op, err)
+	}
+
+	page := models.OrderPage{Orders: orders}
+	if len(orders) > filter.Limit {
+		page.Orders = orders[:filter.Limit]
+		last := page.Orders[len(page.Orders)-1]
+		page.Next = &models.OrderCursor{DateCreated: last.DateCreated, UID: last.UID}
+	}
+
+	if err := s.fillItems(ctx, page.Orders); err != nil {
+		return models.OrderPage{}, wrapErr(op, err)
+	}
+
+	return page, nil
+}
+
+// fillItems loads items of all given orders with a single query.
+func (s *Storage) fillItems(ctx context.Context, orders []models.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	uids := make([]string, 0, len(orders))
+	byUID := make(map[string]*models.Order, len(orders))
+	for i := range orders {
+		orders[i].Items = []models.Item{}
+		uids = append(uids, orders[i].UID)
+		byUID[orders[i].UID] = &orders[i]
+	}
+
+	query := `SELECT order_uid, chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status
+	FROM item WHERE order_uid = ANY($1)`
+
+	rows, err := s.db.Query(ctx, query, uids)
+	if err != nil {
+		return fmt.Errorf("unable to query items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var orderUID string
+		item := models.Item{}
+		err := rows.Scan(&orderUID, &item.ChrtID, &item.TrackNumber, &item.Price, &item.RID, &item.Name, &item.Sale, &item.Size, &item.TotalPrice, &item.NmID, &item.Brand, &item.Status)
+		if err != nil {
+			return fmt.Errorf("unable to scan row: %w", err)
+		}
+
+		order := byUID[orderUID]
+		order.Items = append(order.Items, item)
+	}
+
+	return rows.Err()
+}
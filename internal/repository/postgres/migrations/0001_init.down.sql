@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS dead_letters;
+DROP TABLE IF EXISTS orders;
+DROP TABLE IF EXISTS payment;
+DROP TABLE IF EXISTS delivery;
+DROP TABLE IF EXISTS item;
@@ -0,0 +1,64 @@
+CREATE TABLE IF NOT EXISTS item(
+	chrt_id BIGINT,
+	order_uid VARCHAR(200),
+	track_number TEXT,
+	price BIGINT,
+	rid TEXT,
+	name TEXT,
+	sale INTEGER,
+	size TEXT,
+	total_price BIGINT,
+	nm_id BIGINT,
+	brand TEXT,
+	status BIGINT
+);
+
+CREATE TABLE IF NOT EXISTS delivery(
+	order_uid VARCHAR(200) PRIMARY KEY,
+	name TEXT,
+	phone VARCHAR(20),
+	zip TEXT,
+	city TEXT,
+	adress TEXT,
+	region TEXT,
+	email VARCHAR(330)
+);
+
+CREATE TABLE IF NOT EXISTS payment(
+	order_uid VARCHAR(200) PRIMARY KEY,
+	transaction VARCHAR(200),
+	request_id TEXT,
+	currency VARCHAR(20),
+	provider VARCHAR(100),
+	amount BIGINT,
+	payment_dt BIGINT,
+	bank VARCHAR(100),
+	delivery_cost BIGINT,
+	goods_total BIGINT,
+	custom_fee BIGINT
+);
+
+CREATE TABLE IF NOT EXISTS orders(
+	order_uid VARCHAR(200) PRIMARY KEY,
+	track_number VARCHAR(200),
+	entry VARCHAR(200),
+	locale VARCHAR(30),
+	internal_signature TEXT,
+	customer_id TEXT,
+	delivery_service TEXT,
+	shardkey VARCHAR(30),
+	sm_id BIGINT,
+	date_created timestamp,
+	oof_shard VARCHAR(30)
+);
+
+CREATE TABLE IF NOT EXISTS dead_letters(
+	id BIGSERIAL PRIMARY KEY,
+	subject TEXT,
+	sequence BIGINT,
+	received_at timestamp,
+	payload BYTEA,
+	reason VARCHAR(50),
+	error TEXT,
+	failed_at timestamp
+);
@@ -0,0 +1 @@
+ALTER TABLE delivery RENAME COLUMN address TO adress;
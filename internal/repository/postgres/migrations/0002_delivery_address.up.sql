@@ -0,0 +1 @@
+ALTER TABLE delivery RENAME COLUMN adress TO address;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS item_order_uid_idx;
+
+ALTER TABLE item DROP CONSTRAINT IF EXISTS item_order_uid_fkey;
+ALTER TABLE payment DROP CONSTRAINT IF EXISTS payment_order_uid_fkey;
+ALTER TABLE delivery DROP CONSTRAINT IF EXISTS delivery_order_uid_fkey;
@@ -0,0 +1,38 @@
+-- Rows written before the constraints existed may belong to no order, e.g. when
+-- a save failed half way. They are unreachable through the API and would make
+-- the constraints fail, so they are removed first. The counts go to the server
+-- log as warnings.
+DO $$
+DECLARE
+	removed BIGINT;
+BEGIN
+	DELETE FROM delivery d WHERE NOT EXISTS (SELECT 1 FROM orders o WHERE o.order_uid = d.order_uid);
+	GET DIAGNOSTICS removed = ROW_COUNT;
+	IF removed > 0 THEN
+		RAISE WARNING 'removed % delivery rows without an order', removed;
+	END IF;
+
+	DELETE FROM payment p WHERE NOT EXISTS (SELECT 1 FROM orders o WHERE o.order_uid = p.order_uid);
+	GET DIAGNOSTICS removed = ROW_COUNT;
+	IF removed > 0 THEN
+		RAISE WARNING 'removed % payment rows without an order', removed;
+	END IF;
+
+	DELETE FROM item i WHERE i.order_uid IS NOT NULL
+		AND NOT EXISTS (SELECT 1 FROM orders o WHERE o.order_uid = i.order_uid);
+	GET DIAGNOSTICS removed = ROW_COUNT;
+	IF removed > 0 THEN
+		RAISE WARNING 'removed % item rows without an order', removed;
+	END IF;
+END $$;
+
+ALTER TABLE delivery
+	ADD CONSTRAINT delivery_order_uid_fkey FOREIGN KEY (order_uid) REFERENCES orders (order_uid) ON DELETE CASCADE;
+
+ALTER TABLE payment
+	ADD CONSTRAINT payment_order_uid_fkey FOREIGN KEY (order_uid) REFERENCES orders (order_uid) ON DELETE CASCADE;
+
+ALTER TABLE item
+	ADD CONSTRAINT item_order_uid_fkey FOREIGN KEY (order_uid) REFERENCES orders (order_uid) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS item_order_uid_idx ON item (order_uid);
@@ -0,0 +1,18 @@
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'created';
+
+CREATE TABLE IF NOT EXISTS order_status_history(
+	id BIGSERIAL PRIMARY KEY,
+	order_uid VARCHAR(200) NOT NULL REFERENCES orders (order_uid) ON DELETE CASCADE,
+	from_status VARCHAR(20),
+	status VARCHAR(20) NOT NULL,
+	changed_at timestamp NOT NULL,
+	reason TEXT NOT NULL DEFAULT '',
+	recorded_at timestamp NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS order_status_history_order_uid_idx ON order_status_history (order_uid, changed_at);
+
+-- Orders stored before statuses existed start their timeline at creation.
+INSERT INTO order_status_history (order_uid, status, changed_at)
+SELECT order_uid, 'created', COALESCE(date_created, now())
+FROM orders;
@@ -0,0 +1,237 @@
+package migrations
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// lockID guards against two instances migrating the same database at once.
+const lockID = 7_110_420_001
+
+var fileRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type migration struct {
+	version int64
+	name    string
+	up      string
+	down    string
+}
+
+type Migrator struct {
+	log        *slog.Logger
+	db         *pgxpool.Pool
+	migrations []migration
+}
+
+func New(log *slog.Logger, db *pgxpool.Pool) (*Migrator, error) {
+	const op = "migrations.New"
+
+	migrations, err := load(files)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Migrator{
+		log:        log,
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+// load pairs the up and down scripts in fsys and orders them by version.
+func load(fsys fs.FS) ([]migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*migration{}
+	for _, entry := range entries {
+		match := fileRegexp.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file name %q", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", entry.Name(), err)
+		}
+
+		body, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &migration{version: version, name: match[2]}
+			byVersion[version] = m
+		}
+		if m.name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.up = string(body)
+		} else {
+			m.down = string(body)
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("migration %04d_%s must have both up and down scripts", m.version, m.name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	return migrations, nil
+}
+
+// Up applies every migration newer than the current schema version.
+func (m *Migrator) Up(ctx context.Context) error {
+	const op = "migrations.Up"
+
+	log := m.log.With(slog.String("op", op))
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		current, err := version(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		applied := 0
+		for _, mg := range m.migrations {
+			if mg.version <= current {
+				continue
+			}
+
+			err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, mg.up); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, mg.version, mg.name)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("apply %04d_%s: %w", mg.version, mg.name, err)
+			}
+
+			log.Info("migration applied", slog.Int64("version", mg.version), slog.String("name", mg.name))
+			applied++
+		}
+
+		if applied == 0 {
+			log.Info("schema is up to date", slog.Int64("version", current))
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// Down rolls back the given number of most recently applied migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	const op = "migrations.Down"
+
+	log := m.log.With(slog.String("op", op))
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+			mg := m.migrations[i]
+
+			current, err := version(ctx, conn)
+			if err != nil {
+				return err
+			}
+			if mg.version > current {
+				continue
+			}
+			if mg.version < current {
+				return fmt.Errorf("applied version %d has no migration script", current)
+			}
+
+			err = pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, mg.down); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mg.version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("roll back %04d_%s: %w", mg.version, mg.name, err)
+			}
+
+			log.Info("migration rolled back", slog.Int64("version", mg.version), slog.String("name", mg.name))
+			steps--
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// Version returns the current schema version, 0 if nothing is applied.
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	const op = "migrations.Version"
+
+	var current int64
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) (err error) {
+		current, err = version(ctx, conn)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return current, nil
+}
+
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockID); err != nil {
+		return err
+	}
+	defer conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, lockID)
+
+	if _, err := conn.Exec(ctx, `
+	CREATE TABLE IF NOT EXISTS schema_migrations(
+		version BIGINT PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at timestamp NOT NULL DEFAULT now()
+		);
+	`); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func version(ctx context.Context, conn *pgxpool.Conn) (int64, error) {
+	var current int64
+	err := conn.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current)
+	return current, err
+}
@@ -0,0 +1,116 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func script(sql string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(sql)}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0010_tenth.up.sql":     script("up 10"),
+		"0010_tenth.down.sql":   script("down 10"),
+		"0002_second.down.sql":  script("down 2"),
+		"0002_second.up.sql":    script("up 2"),
+		"0001_init.up.sql":      script("up 1"),
+		"0001_init.down.sql":    script("down 1"),
+		"0009_ninth_a.up.sql":   script("up 9"),
+		"0009_ninth_a.down.sql": script("down 9"),
+	}
+
+	got, err := load(fsys)
+	if err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+
+	want := []migration{
+		{version: 1, name: "init", up: "up 1", down: "down 1"},
+		{version: 2, name: "second", up: "up 2", down: "down 2"},
+		{version: 9, name: "ninth_a", up: "up 9", down: "down 9"},
+		{version: 10, name: "tenth", up: "up 10", down: "down 10"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("load() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("load()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+		want string
+	}{
+		{
+			name: "unexpected file name",
+			fsys: fstest.MapFS{"init.sql": script("")},
+			want: "unexpected migration file name",
+		},
+		{
+			name: "unknown direction",
+			fsys: fstest.MapFS{"0001_init.sideways.sql": script("")},
+			want: "unexpected migration file name",
+		},
+		{
+			name: "name with a dash",
+			fsys: fstest.MapFS{"0001_add-index.up.sql": script("")},
+			want: "unexpected migration file name",
+		},
+		{
+			name: "version out of range",
+			fsys: fstest.MapFS{"99999999999999999999_init.up.sql": script("")},
+			want: "invalid migration version",
+		},
+		{
+			name: "missing down script",
+			fsys: fstest.MapFS{"0001_init.up.sql": script("up 1")},
+			want: "must have both up and down scripts",
+		},
+		{
+			name: "missing up script",
+			fsys: fstest.MapFS{"0001_init.down.sql": script("down 1")},
+			want: "must have both up and down scripts",
+		},
+		{
+			name: "conflicting names",
+			fsys: fstest.MapFS{
+				"0001_init.up.sql":    script("up 1"),
+				"0001_start.down.sql": script("down 1"),
+			},
+			want: "conflicting names",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := load(tt.fsys)
+			if err == nil {
+				t.Fatalf("load() = %+v, want error", got)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("load() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	got, err := load(files)
+	if err != nil {
+		t.Fatalf("load(files) error = %v", err)
+	}
+
+	for i, m := range got {
+		if m.version != int64(i+1) {
+			t.Errorf("migration %04d_%s is at position %d, want versions without gaps", m.version, m.name, i)
+		}
+	}
+}
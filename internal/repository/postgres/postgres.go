@@ -1,138 +1,96 @@
 package postgres
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/patrickmn/go-cache"
+	"golang.org/x/sync/singleflight"
+	"log/slog"
+	"net"
+	"reflect"
+	"slices"
+	"strings"
 	"time"
+	"wbnats/internal/lib/cache"
+	"wbnats/internal/repository"
+	"wbnats/internal/repository/postgres/migrations"
 	"wbnats/internal/services/order/models"
 )
 
 type Storage struct {
 	db    *pgxpool.Pool
-	cache *cache.Cache
+	cache cache.Cache[*models.Order]
+	group singleflight.Group
+}
+
+// loadTimeout bounds a read-through load shared by concurrent callers, so one
+// caller giving up doesn't cancel it for the others.
+const loadTimeout = 10 * time.Second
+
+const (
+	uniqueViolationCode               = "23505"
+	integrityConstraintViolationClass = "23"
+)
+
+// querier is satisfied by both the pool and a transaction.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 }
 
 func New(host string,
 	port string,
 	dbName string,
 	user string,
-	pass string) (*Storage, error) {
+	pass string,
+	orderCache cache.Cache[*models.Order]) (*Storage, error) {
 	const op = "repository.postgres.New"
 	pool, err := pgxpool.New(context.Background(), fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", host, port, user, pass, dbName))
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	_, err = pool.Exec(context.Background(), `
-	CREATE TABLE IF NOT EXISTS item(
-		chrt_id BIGINT,
-	    order_uid VARCHAR(200),
-		track_number TEXT,
-		price BIGINT,
-		rid TEXT,
-		name TEXT,
-		sale INTEGER,
-		size TEXT,
-		total_price BIGINT,
-		nm_id BIGINT,
-		brand TEXT,
-		status BIGINT
-	                               );
-
-	CREATE TABLE IF NOT EXISTS delivery(
-		order_uid VARCHAR(200) PRIMARY KEY,
-		name TEXT,
-		phone VARCHAR(20),
-		zip TEXT,
-		city TEXT,
-		adress TEXT,
-		region TEXT,
-		email VARCHAR(330)
-	                                   );
-
-	CREATE TABLE IF NOT EXISTS payment(
-		order_uid VARCHAR(200) PRIMARY KEY,
-		transaction VARCHAR(200),
-		request_id TEXT,
-		currency VARCHAR(20),
-		provider VARCHAR(100),
-		amount BIGINT,
-		payment_dt BIGINT,
-		bank VARCHAR(100),
-		delivery_cost BIGINT,
-		goods_total BIGINT,
-		custom_fee BIGINT
-	                                  );
-
-	CREATE TABLE IF NOT EXISTS orders(
-		order_uid VARCHAR(200) PRIMARY KEY,
-		track_number VARCHAR(200),
-		entry VARCHAR(200),
-		locale VARCHAR(30),
-		internal_signature TEXT,
-		customer_id TEXT,
-		delivery_service TEXT,
-		shardkey VARCHAR(30),
-		sm_id BIGINT,
-		date_created timestamp,
-		oof_shard VARCHAR(30)
-		);
-	`)
-
-	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
-	}
-
-	ch := cache.New(5*time.Minute, 10*time.Minute)
-
-	return &Storage{db: pool, cache: ch}, nil
+	return &Storage{db: pool, cache: orderCache}, nil
 }
 
-func (s *Storage) RestoreCache() {
-	orders, err := s.GetOrders(context.Background())
-	if err != nil {
-		return
-	}
-	for _, ord := range orders {
-		s.cache.Set(ord.UID, &ord, cache.NoExpiration)
-	}
+func (s *Storage) Close() {
+	s.db.Close()
 }
 
-func (s *Storage) GetOrders(ctx context.Context) ([]models.Order, error) {
-	query := `SELECT orders.order_uid, transaction, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard, name, phone, zip, city, adress, region, email  
-				FROM orders
-    			JOIN payment ON orders.order_uid = payment.order_uid
-    			JOIN delivery ON orders.order_uid = delivery.order_uid`
+func (s *Storage) Ping(ctx context.Context) error {
+	const op = "repository.postgres.Ping"
 
-	rows, err := s.db.Query(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("unable to query orders: %w", err)
+	if err := s.db.Ping(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
 	}
-	defer rows.Close()
+	return nil
+}
 
-	orders := []models.Order{}
-	for rows.Next() {
-		order := models.Order{}
-		err := rows.Scan(&order.UID, &order.Payment.Transaction, &order.Payment.RequestID, &order.Payment.Currency, &order.Payment.Provider, &order.Payment.Amount, &order.Payment.PaymentDT, &order.Payment.Bank, &order.Payment.DeliveryCost, &order.Payment.GoodsTotal, &order.Payment.CustomFee, &order.TrackNumber, &order.Entry, &order.Locale, &order.InternalSignature, &order.CustomerID, &order.DeliveryService, &order.Shardkey, &order.SmID, &order.DateCreated, &order.OofShard, &order.Delivery.Name, &order.Delivery.Phone, &order.Delivery.Zip, &order.Delivery.City, &order.Delivery.Address, &order.Delivery.Region, &order.Delivery.Email)
-		if err != nil {
-			return nil, fmt.Errorf("unable to scan row: %w", err)
-		}
-		items, err := s.GetItems(ctx, order.UID)
-		order.Items = items
-		orders = append(orders, order)
-	}
+func (s *Storage) PoolStats() *pgxpool.Stat {
+	return s.db.Stat()
+}
+
+func (s *Storage) CacheStats() cache.Stats {
+	return s.cache.Stats()
+}
 
-	return orders, nil
+func (s *Storage) Migrator(log *slog.Logger) (*migrations.Migrator, error) {
+	return migrations.New(log, s.db)
 }
 
 func (s *Storage) GetItems(ctx context.Context, orderUID string) ([]models.Item, error) {
+	return getItems(ctx, s.db, orderUID)
+}
+
+func getItems(ctx context.Context, q querier, orderUID string) ([]models.Item, error) {
 	query := `SELECT chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status
 	FROM item WHERE order_uid = $1`
 
-	rows, err := s.db.Query(ctx, query, orderUID)
+	rows, err := q.Query(ctx, query, orderUID)
 	if err != nil {
 		return nil, fmt.Errorf("unable to query orders: %w", err)
 	}
@@ -152,9 +110,42 @@ func (s *Storage) GetItems(ctx context.Context, orderUID string) ([]models.Item,
 	return items, nil
 }
 
-func (s *Storage) SaveOrder(order *models.Order) (err error) {
+func orderByUID(ctx context.Context, q querier, uid string) (models.Order, error) {
+	query := `SELECT orders.order_uid, transaction, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard, orders.status, orders.version, name, phone, zip, city, address, region, email
+				FROM orders
+				JOIN payment ON orders.order_uid = payment.order_uid
+				JOIN delivery ON orders.order_uid = delivery.order_uid
+				WHERE orders.order_uid = $1`
+
+	order := models.Order{}
+	err := q.QueryRow(ctx, query, uid).Scan(&order.UID, &order.Payment.Transaction, &order.Payment.RequestID, &order.Payment.Currency, &order.Payment.Provider, &order.Payment.Amount, &order.Payment.PaymentDT, &order.Payment.Bank, &order.Payment.DeliveryCost, &order.Payment.GoodsTotal, &order.Payment.CustomFee, &order.TrackNumber, &order.Entry, &order.Locale, &order.InternalSignature, &order.CustomerID, &order.DeliveryService, &order.Shardkey, &order.SmID, &order.DateCreated, &order.OofShard, &order.Status, &order.Version, &order.Delivery.Name, &order.Delivery.Phone, &order.Delivery.Zip, &order.Delivery.City, &order.Delivery.Address, &order.Delivery.Region, &order.Delivery.Email)
+	if err != nil {
+		return models.Order{}, fmt.Errorf("unable to query order: %w", err)
+	}
+
+	order.Items, err = getItems(ctx, q, uid)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	return order, nil
+}
+
+// SaveOrder inserts the order with its delivery, payment and items in one
+// transaction unless one with the same UID already exists. An identical
+// re-delivery is reported as a duplicate, a different payload under the same
+// UID as a conflict. Once the order has been updated its creation message can
+// no longer be compared, so any re-delivery of it counts as a duplicate. The
+// cache is updated only after commit.
+func (s *Storage) SaveOrder(ctx context.Context, order *models.Order) (models.SaveResult, error) {
+	const op = "repository.postgres.SaveOrder"
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, wrapErr(op, err)
+	}
+	defer tx.Rollback(ctx)
 
-	batch := &pgx.Batch{}
 	orderQuery := `INSERT INTO orders (
                    order_uid,
                    track_number,
@@ -166,7 +157,9 @@ func (s *Storage) SaveOrder(order *models.Order) (err error) {
                    shardkey,
                    sm_id,
                    date_created,
-                   oof_shard
+                   oof_shard,
+                   status,
+                   version
                    ) VALUES (
 							@orderUID,
 							@trackNumber,
@@ -178,7 +171,10 @@ func (s *Storage) SaveOrder(order *models.Order) (err error) {
 							@shardkey,
 							@smID,
 							@dateCreated,
-							@oofShard)`
+							@oofShard,
+							@status,
+							@version)
+					ON CONFLICT (order_uid) DO NOTHING`
 	orderArgs := pgx.NamedArgs{
 		"orderUID":          order.UID,
 		"trackNumber":       order.TrackNumber,
@@ -191,8 +187,33 @@ func (s *Storage) SaveOrder(order *models.Order) (err error) {
 		"smID":              order.SmID,
 		"dateCreated":       order.DateCreated,
 		"oofShard":          order.OofShard,
+		"status":            order.Status,
+		"version":           order.Version,
+	}
+
+	tag, err := tx.Exec(ctx, orderQuery, orderArgs)
+	if err != nil {
+		return 0, wrapErr(op, err)
 	}
-	batch.Queue(orderQuery, orderArgs)
+
+	if tag.RowsAffected() == 0 {
+		existing, err := orderByUID(ctx, tx, order.UID)
+		if err != nil {
+			return 0, wrapErr(op, err)
+		}
+
+		if existing.Version <= 1 && !sameOrder(&existing, order) {
+			return models.SaveResultConflict, fmt.Errorf("%s: %w", op, repository.ErrConflict)
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return 0, wrapErr(op, err)
+		}
+		s.cache.Add(order.UID, &existing)
+		return models.SaveResultDuplicate, nil
+	}
+
+	batch := &pgx.Batch{}
 
 	deliveryQuery := `INSERT INTO delivery (
 						order_uid,
@@ -200,7 +221,7 @@ func (s *Storage) SaveOrder(order *models.Order) (err error) {
 						phone,
 						zip,
 						city,
-						adress,
+						address,
 						region,
 						email
 						 ) VALUES (
@@ -209,7 +230,7 @@ func (s *Storage) SaveOrder(order *models.Order) (err error) {
 							   @phone,
 							   @zip,
 							   @city,
-							   @adress,
+							   @address,
 							   @region,
 							   @email
 							   )`
@@ -219,7 +240,7 @@ func (s *Storage) SaveOrder(order *models.Order) (err error) {
 		"phone":    order.Delivery.Phone,
 		"zip":      order.Delivery.Zip,
 		"city":     order.Delivery.City,
-		"adress":   order.Delivery.Address,
+		"address":  order.Delivery.Address,
 		"region":   order.Delivery.Region,
 		"email":    order.Delivery.Email,
 	}
@@ -266,6 +287,24 @@ func (s *Storage) SaveOrder(order *models.Order) (err error) {
 
 	batch.Queue(paymentQuery, paymentArgs)
 
+	queueItems(batch, order)
+
+	batch.Queue(`INSERT INTO order_status_history (order_uid, status, changed_at) VALUES ($1, $2, $3)`,
+		order.UID, order.Status, order.DateCreated)
+
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return 0, wrapErr(op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, wrapErr(op, err)
+	}
+	s.cache.Set(order.UID, order)
+	return models.SaveResultInserted, nil
+}
+
+// queueItems queues inserts of all items of the order.
+func queueItems(batch *pgx.Batch, order *models.Order) {
 	itemQuery := `INSERT INTO item (
 		chrt_id,
 	   order_uid,
@@ -310,20 +349,130 @@ func (s *Storage) SaveOrder(order *models.Order) (err error) {
 		}
 		batch.Queue(itemQuery, itemArgs)
 	}
-	results := s.db.SendBatch(context.Background(), batch)
 
-	if results.Close() != nil {
-		return err
+}
+
+// sameOrder compares two orders ignoring item order, time zone, status and
+// version, which move on after the order is stored.
+func sameOrder(a, b *models.Order) bool {
+	normalize := func(o *models.Order) models.Order {
+		n := *o
+		n.Status = ""
+		n.Version = 0
+		n.DateCreated = o.DateCreated.UTC()
+		n.Items = slices.Clone(o.Items)
+		slices.SortFunc(n.Items, func(x, y models.Item) int {
+			if c := cmp.Compare(x.ChrtID, y.ChrtID); c != 0 {
+				return c
+			}
+			return cmp.Compare(x.RID, y.RID)
+		})
+		if n.Items == nil {
+			n.Items = []models.Item{}
+		}
+		return n
 	}
-	s.cache.Set(order.UID, order, cache.NoExpiration)
-	return nil
+
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+// wrapErr wraps err with op and the matching repository error so callers can
+// tell connection failures from data errors.
+func wrapErr(op string, err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == uniqueViolationCode:
+			return fmt.Errorf("%s: %w: %w", op, repository.ErrDuplicate, err)
+		case strings.HasPrefix(pgErr.Code, integrityConstraintViolationClass):
+			return fmt.Errorf("%s: %w: %w", op, repository.ErrConstraintViolation, err)
+		}
+	}
+
+	if isUnavailable(err) {
+		return fmt.Errorf("%s: %w: %w", op, repository.ErrUnavailable, err)
+	}
+	return fmt.Errorf("%s: %w", op, err)
 }
 
 func (s *Storage) Order(ctx context.Context, uid string) (models.Order, error) {
+	const op = "repository.postgres.Order"
 
-	if x, found := s.cache.Get(uid); found {
-		ord := x.(*models.Order)
+	if ord, found := s.cache.Get(uid); found {
 		return *ord, nil
 	}
-	return models.Order{}, fmt.Errorf("no data")
+
+	ch := s.group.DoChan(uid, func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+		defer cancel()
+
+		order, err := orderByUID(ctx, s.db, uid)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, repository.ErrNotFound
+			}
+			return nil, err
+		}
+
+		s.cache.Add(uid, &order)
+		return &order, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return models.Order{}, fmt.Errorf("%s: %w", op, ctx.Err())
+	case res := <-ch:
+		if res.Err != nil {
+			return models.Order{}, wrapErr(op, res.Err)
+		}
+		return *res.Val.(*models.Order), nil
+	}
+}
+
+func (s *Storage) SaveDeadLetter(ctx context.Context, deadLetter *models.DeadLetter) error {
+	const op = "repository.postgres.SaveDeadLetter"
+
+	query := `INSERT INTO dead_letters (
+					subject,
+					sequence,
+					received_at,
+					payload,
+					reason,
+					error,
+					failed_at
+					) VALUES (
+						@subject,
+						@sequence,
+						@receivedAt,
+						@payload,
+						@reason,
+						@error,
+						@failedAt)`
+	args := pgx.NamedArgs{
+		"subject":    deadLetter.Subject,
+		"sequence":   int64(deadLetter.Sequence),
+		"receivedAt": deadLetter.ReceivedAt,
+		"payload":    deadLetter.Payload,
+		"reason":     deadLetter.Reason,
+		"error":      deadLetter.Error,
+		"failedAt":   deadLetter.FailedAt,
+	}
+
+	if _, err := s.db.Exec(ctx, query, args); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// isUnavailable reports whether err was caused by the database being
+// unreachable rather than by the data sent to it.
+func isUnavailable(err error) bool {
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+
+	return errors.As(err, &connectErr) ||
+		errors.As(err, &netErr) ||
+		pgconn.Timeout(err) ||
+		pgconn.SafeToRetry(err) ||
+		errors.Is(err, context.DeadlineExceeded)
 }
@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+	"wbnats/internal/services/order/models"
+)
+
+func testOrder() *models.Order {
+	return &models.Order{
+		UID:         "b563feb7b2b84b6test",
+		TrackNumber: "WBILMTESTTRACK",
+		Entry:       "WBIL",
+		Delivery: models.Delivery{
+			Name:  "Test Testov",
+			Phone: "+9720000000",
+			City:  "Kiryat Mozkin",
+			Email: "test@gmail.com",
+		},
+		Payment: models.Payment{
+			Transaction:  "b563feb7b2b84b6test",
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       1817,
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+		},
+		Items: []models.Item{
+			{ChrtID: 1, TrackNumber: "WBILMTESTTRACK", RID: "r1", TotalPrice: 100},
+			{ChrtID: 2, TrackNumber: "WBILMTESTTRACK", RID: "r2", TotalPrice: 117},
+			{ChrtID: 2, TrackNumber: "WBILMTESTTRACK", RID: "r3", TotalPrice: 100},
+		},
+		Locale:      "en",
+		CustomerID:  "test",
+		DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		Status:      models.StatusCreated,
+		Version:     1,
+	}
+}
+
+func TestSameOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(o *models.Order)
+		want   bool
+	}{
+		{
+			name:   "identical",
+			modify: func(o *models.Order) {},
+			want:   true,
+		},
+		{
+			name: "items in another order",
+			modify: func(o *models.Order) {
+				o.Items[0], o.Items[1], o.Items[2] = o.Items[2], o.Items[0], o.Items[1]
+			},
+			want: true,
+		},
+		{
+			name: "date in another zone",
+			modify: func(o *models.Order) {
+				o.DateCreated = o.DateCreated.In(time.FixedZone("MSK", 3*60*60))
+			},
+			want: true,
+		},
+		{
+			name:   "status and version changed since",
+			modify: func(o *models.Order) { o.Status, o.Version = models.StatusPaid, 2 },
+			want:   true,
+		},
+		{
+			name:   "payment amount changed",
+			modify: func(o *models.Order) { o.Payment.Amount = 1900 },
+		},
+		{
+			name:   "payment provider changed",
+			modify: func(o *models.Order) { o.Payment.Provider = "other" },
+		},
+		{
+			name:   "delivery address changed",
+			modify: func(o *models.Order) { o.Delivery.City = "Haifa" },
+		},
+		{
+			name:   "item changed",
+			modify: func(o *models.Order) { o.Items[2].TotalPrice = 101 },
+		},
+		{
+			name:   "item missing",
+			modify: func(o *models.Order) { o.Items = o.Items[:2] },
+		},
+		{
+			name:   "date changed",
+			modify: func(o *models.Order) { o.DateCreated = o.DateCreated.Add(time.Second) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := testOrder(), testOrder()
+			tt.modify(b)
+
+			if got := sameOrder(a, b); got != tt.want {
+				t.Errorf("sameOrder() = %v, want %v", got, tt.want)
+			}
+			if got := sameOrder(b, a); got != tt.want {
+				t.Errorf("sameOrder() reversed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSameOrderNilAndEmptyItems(t *testing.T) {
+	a, b := testOrder(), testOrder()
+	a.Items, b.Items = nil, []models.Item{}
+
+	if !sameOrder(a, b) {
+		t.Error("sameOrder() = false for nil and empty items, want true")
+	}
+}
+
+func TestSameOrderLeavesItemsUnsorted(t *testing.T) {
+	a, b := testOrder(), testOrder()
+	b.Items[0], b.Items[2] = b.Items[2], b.Items[0]
+
+	sameOrder(a, b)
+
+	if b.Items[0].RID != "r3" {
+		t.Errorf("sameOrder() reordered the caller's items: %v", b.Items)
+	}
+}
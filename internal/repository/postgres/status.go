@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"slices"
+	"wbnats/internal/repository"
+	"wbnats/internal/services/order/models"
+)
+
+// SetOrderStatus moves the order to change.Status if its current status is one
+// of from, recording the step in the history. Otherwise it returns the current
+// status with repository.ErrConflict. The cached order is replaced only after
+// commit.
+func (s *Storage) SetOrderStatus(ctx context.Context, change *models.StatusChange, from []models.Status) (models.Status, error) {
+	const op = "repository.postgres.SetOrderStatus"
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return "", wrapErr(op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var current models.Status
+	err = tx.QueryRow(ctx, `SELECT status FROM orders WHERE order_uid = $1 FOR UPDATE`, change.OrderUID).Scan(&current)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", fmt.Errorf("%s: %w", op, repository.ErrNotFound)
+		}
+		return "", wrapErr(op, err)
+	}
+
+	if !slices.Contains(from, current) {
+		return current, fmt.Errorf("%s: %w", op, repository.ErrConflict)
+	}
+
+	batch := &pgx.Batch{}
+	batch.Queue(`UPDATE orders SET status = $2 WHERE order_uid = $1`, change.OrderUID, change.Status)
+	batch.Queue(`INSERT INTO order_status_history (order_uid, from_status, status, changed_at, reason) VALUES ($1, $2, $3, $4, $5)`,
+		change.OrderUID, current, change.Status, change.ChangedAt, change.Reason)
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return current, wrapErr(op, err)
+	}
+
+	// Refreshing rather than dropping the entry keeps a concurrent read-through
+	// load of the old row from caching it again.
+	order, err := orderByUID(ctx, tx, change.OrderUID)
+	if err != nil {
+		return current, wrapErr(op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return current, wrapErr(op, err)
+	}
+	s.cache.Set(order.UID, &order)
+	return current, nil
+}
+
+// OrderStatusHistory returns the status timeline of the order, oldest first.
+func (s *Storage) OrderStatusHistory(ctx context.Context, uid string) ([]models.StatusChange, error) {
+	const op = "repository.postgres.OrderStatusHistory"
+
+	rows, err := s.db.Query(ctx, `SELECT COALESCE(from_status, ''), status, changed_at, reason
+				FROM order_status_history
+				WHERE order_uid = $1
+				ORDER BY changed_at, id`, uid)
+	if err != nil {
+		return nil, wrapErr(op, err)
+	}
+	defer rows.Close()
+
+	history := []models.StatusChange{}
+	for rows.Next() {
+		change := models.StatusChange{OrderUID: uid}
+		if err := rows.Scan(&change.From, &change.Status, &change.ChangedAt, &change.Reason); err != nil {
+			return nil, wrapErr(op, err)
+		}
+		history = append(history, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrapErr(op, err)
+	}
+
+	// Every stored order has at least its created entry.
+	if len(history) == 0 {
+		return nil, fmt.Errorf("%s: %w", op, repository.ErrNotFound)
+	}
+	return history, nil
+}
@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"wbnats/internal/repository"
+	"wbnats/internal/services/order/models"
+)
+
+// UpdateOrder locks the order, checks it is still at version, lets apply
+// change it and writes it back with the version incremented. A version
+// mismatch is reported as repository.ErrConflict. The cache is refreshed only
+// after commit.
+func (s *Storage) UpdateOrder(ctx context.Context, uid string, version int64, apply func(order *models.Order) error) (models.Order, error) {
+	const op = "repository.postgres.UpdateOrder"
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return models.Order{}, wrapErr(op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var current int64
+	err = tx.QueryRow(ctx, `SELECT version FROM orders WHERE order_uid = $1 FOR UPDATE`, uid).Scan(&current)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Order{}, fmt.Errorf("%s: %w", op, repository.ErrNotFound)
+		}
+		return models.Order{}, wrapErr(op, err)
+	}
+	if current != version {
+		return models.Order{}, fmt.Errorf("%s: stored version is %d, update is based on %d: %w", op, current, version, repository.ErrConflict)
+	}
+
+	order, err := orderByUID(ctx, tx, uid)
+	if err != nil {
+		return models.Order{}, wrapErr(op, err)
+	}
+
+	if err := apply(&order); err != nil {
+		return models.Order{}, fmt.Errorf("%s: %w", op, err)
+	}
+	order.Version = current + 1
+
+	batch := &pgx.Batch{}
+
+	batch.Queue(`UPDATE orders SET
+					track_number = @trackNumber,
+					entry = @entry,
+					locale = @locale,
+					internal_signature = @internalSignature,
+					customer_id = @customerID,
+					delivery_service = @deliveryService,
+					shardkey = @shardkey,
+					sm_id = @smID,
+					oof_shard = @oofShard,
+					version = @version
+				WHERE order_uid = @orderUID`, pgx.NamedArgs{
+		"orderUID":          order.UID,
+		"trackNumber":       order.TrackNumber,
+		"entry":             order.Entry,
+		"locale":            order.Locale,
+		"internalSignature": order.InternalSignature,
+		"customerID":        order.CustomerID,
+		"deliveryService":   order.DeliveryService,
+		"shardkey":          order.Shardkey,
+		"smID":              order.SmID,
+		"oofShard":          order.OofShard,
+		"version":           order.Version,
+	})
+
+	batch.Queue(`UPDATE delivery SET
+					name = @name,
+					phone = @phone,
+					zip = @zip,
+					city = @city,
+					address = @address,
+					region = @region,
+					email = @email
+				WHERE order_uid = @orderUID`, pgx.NamedArgs{
+		"orderUID": order.UID,
+		"name":     order.Delivery.Name,
+		"phone":    order.Delivery.Phone,
+		"zip":      order.Delivery.Zip,
+		"city":     order.Delivery.City,
+		"address":  order.Delivery.Address,
+		"region":   order.Delivery.Region,
+		"email":    order.Delivery.Email,
+	})
+
+	batch.Queue(`UPDATE payment SET
+					transaction = @transaction,
+					request_id = @requestID,
+					currency = @currency,
+					provider = @provider,
+					amount = @amount,
+					payment_dt = @paymentDT,
+					bank = @bank,
+					delivery_cost = @deliveryCost,
+					goods_total = @goodsTotal,
+					custom_fee = @customFee
+				WHERE order_uid = @orderUID`, pgx.NamedArgs{
+		"orderUID":     order.UID,
+		"transaction":  order.Payment.Transaction,
+		"requestID":    order.Payment.RequestID,
+		"currency":     order.Payment.Currency,
+		"provider":     order.Payment.Provider,
+		"amount":       order.Payment.Amount,
+		"paymentDT":    order.Payment.PaymentDT,
+		"bank":         order.Payment.Bank,
+		"deliveryCost": order.Payment.DeliveryCost,
+		"goodsTotal":   order.Payment.GoodsTotal,
+		"customFee":    order.Payment.CustomFee,
+	})
+
+	batch.Queue(`DELETE FROM item WHERE order_uid = $1`, order.UID)
+	queueItems(batch, &order)
+
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return models.Order{}, wrapErr(op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.Order{}, wrapErr(op, err)
+	}
+	s.cache.Set(order.UID, &order)
+	return order, nil
+}
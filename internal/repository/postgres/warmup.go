@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	"wbnats/internal/services/order/models"
+)
+
+// itemRow mirrors the json_build_object in the warm-up query.
+type itemRow struct {
+	ChrtID      int64  `json:"chrt_id"`
+	TrackNumber string `json:"track_number"`
+	Price       int64  `json:"price"`
+	RID         string `json:"rid"`
+	Name        string `json:"name"`
+	Sale        int16  `json:"sale"`
+	Size        string `json:"size"`
+	TotalPrice  int64  `json:"total_price"`
+	NmID        int64  `json:"nm_id"`
+	Brand       string `json:"brand"`
+	Status      int64  `json:"status"`
+}
+
+// WarmUp loads orders created since the given time (all orders when zero)
+// into the cache, oldest first so the most recent ones survive eviction.
+// Orders already cached are kept, as they may have changed since the read.
+// Orders and their items come in one query per batch of batchSize orders;
+// progress is called after every batch with the total loaded so far.
+func (s *Storage) WarmUp(ctx context.Context, since time.Time, batchSize int, progress func(loaded int)) (int, error) {
+	const op = "repository.postgres.WarmUp"
+
+	query := `SELECT orders.order_uid, transaction, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard, orders.status, orders.version, name, phone, zip, city, address, region, email,
+				COALESCE((
+					SELECT json_agg(json_build_object(
+						'chrt_id', item.chrt_id,
+						'track_number', item.track_number,
+						'price', item.price,
+						'rid', item.rid,
+						'name', item.name,
+						'sale', item.sale,
+						'size', item.size,
+						'total_price', item.total_price,
+						'nm_id', item.nm_id,
+						'brand', item.brand,
+						'status', item.status))
+					FROM item WHERE item.order_uid = orders.order_uid
+				), '[]'::json)
+				FROM orders
+				JOIN payment ON orders.order_uid = payment.order_uid
+				JOIN delivery ON orders.order_uid = delivery.order_uid
+				WHERE orders.date_created >= $1
+					AND (orders.date_created, orders.order_uid) > ($2, $3)
+				ORDER BY orders.date_created, orders.order_uid
+				LIMIT $4`
+
+	var (
+		loaded      int
+		lastCreated = since
+		lastUID     = ""
+	)
+	for {
+		batch, err := s.warmUpBatch(ctx, query, since, lastCreated, lastUID, batchSize)
+		if err != nil {
+			return loaded, wrapErr(op, err)
+		}
+
+		for i := range batch {
+			s.cache.Add(batch[i].UID, &batch[i])
+		}
+		loaded += len(batch)
+
+		if progress != nil {
+			progress(loaded)
+		}
+
+		if len(batch) < batchSize {
+			return loaded, nil
+		}
+
+		last := batch[len(batch)-1]
+		lastCreated, lastUID = last.DateCreated, last.UID
+	}
+}
+
+func (s *Storage) warmUpBatch(ctx context.Context, query string, since, lastCreated time.Time, lastUID string, batchSize int) ([]models.Order, error) {
+	rows, err := s.db.Query(ctx, query, since, lastCreated, lastUID, batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := make([]models.Order, 0, batchSize)
+	for rows.Next() {
+		order := models.Order{}
+		var itemsJSON []byte
+		err := rows.Scan(&order.UID, &order.Payment.Transaction, &order.Payment.RequestID, &order.Payment.Currency, &order.Payment.Provider, &order.Payment.Amount, &order.Payment.PaymentDT, &order.Payment.Bank, &order.Payment.DeliveryCost, &order.Payment.GoodsTotal, &order.Payment.CustomFee, &order.TrackNumber, &order.Entry, &order.Locale, &order.InternalSignature, &order.CustomerID, &order.DeliveryService, &order.Shardkey, &order.SmID, &order.DateCreated, &order.OofShard, &order.Status, &order.Version, &order.Delivery.Name, &order.Delivery.Phone, &order.Delivery.Zip, &order.Delivery.City, &order.Delivery.Address, &order.Delivery.Region, &order.Delivery.Email, &itemsJSON)
+		if err != nil {
+			return nil, fmt.Errorf("unable to scan row: %w", err)
+		}
+
+		var items []itemRow
+		if err := json.Unmarshal(itemsJSON, &items); err != nil {
+			return nil, fmt.Errorf("unable to decode items of order %s: %w", order.UID, err)
+		}
+
+		order.Items = make([]models.Item, 0, len(items))
+		for _, item := range items {
+			order.Items = append(order.Items, models.Item(item))
+		}
+
+		orders = append(orders, order)
+	}
+
+	return orders, rows.Err()
+}
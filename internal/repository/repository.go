@@ -0,0 +1,12 @@
+package repository
+
+import "errors"
+
+var (
+	ErrNotFound    = errors.New("order not found")
+	ErrUnavailable = errors.New("storage is unavailable")
+	ErrConflict    = errors.New("order already exists with different data")
+	ErrDuplicate   = errors.New("record already exists")
+
+	ErrConstraintViolation = errors.New("constraint violation")
+)
@@ -0,0 +1,68 @@
+package deadLetterService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"wbnats/internal/lib/logger/sl"
+	"wbnats/internal/services/order/models"
+)
+
+var ErrNoSinks = errors.New("no dead-letter sink configured")
+
+type DeadLetter struct {
+	log   *slog.Logger
+	sinks []Sink
+}
+
+type Sink interface {
+	SaveDeadLetter(ctx context.Context, deadLetter *models.DeadLetter) error
+}
+
+func New(
+	log *slog.Logger,
+	sinks ...Sink,
+) *DeadLetter {
+	return &DeadLetter{
+		log:   log,
+		sinks: sinks,
+	}
+}
+
+func (d *DeadLetter) AddSink(sink Sink) {
+	d.sinks = append(d.sinks, sink)
+}
+
+// Send hands the message to every sink. It only fails when no sink accepted
+// the message, so one broken destination doesn't lose it, or when there is no
+// sink at all.
+func (d *DeadLetter) Send(ctx context.Context, deadLetter *models.DeadLetter) error {
+	const op = "DeadLetter.Send"
+
+	log := d.log.With(
+		slog.String("op", op),
+		slog.String("subject", deadLetter.Subject),
+		slog.Uint64("sequence", deadLetter.Sequence),
+		slog.String("reason", deadLetter.Reason),
+	)
+
+	if len(d.sinks) == 0 {
+		return fmt.Errorf("%s: %w", op, ErrNoSinks)
+	}
+
+	log.Warn("dead-lettering message")
+
+	var errs []error
+	for _, sink := range d.sinks {
+		if err := sink.SaveDeadLetter(ctx, deadLetter); err != nil {
+			log.Error("dead-letter sink failed", sl.Err(err))
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == len(d.sinks) {
+		return fmt.Errorf("%s: %w", op, errors.Join(errs...))
+	}
+	return nil
+}
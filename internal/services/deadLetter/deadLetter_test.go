@@ -0,0 +1,60 @@
+package deadLetterService
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"wbnats/internal/services/order/models"
+)
+
+type fakeSink struct {
+	err   error
+	saved int
+}
+
+func (f *fakeSink) SaveDeadLetter(context.Context, *models.DeadLetter) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.saved++
+	return nil
+}
+
+func TestSend(t *testing.T) {
+	broken := errors.New("sink unavailable")
+
+	tests := []struct {
+		name    string
+		sinks   []*fakeSink
+		wantErr bool
+	}{
+		{name: "no sinks", wantErr: true},
+		{name: "one sink", sinks: []*fakeSink{{}}},
+		{name: "one of two sinks failing", sinks: []*fakeSink{{err: broken}, {}}},
+		{name: "every sink failing", sinks: []*fakeSink{{err: broken}, {err: broken}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(slog.New(slog.NewTextHandler(io.Discard, nil)))
+			for _, sink := range tt.sinks {
+				d.AddSink(sink)
+			}
+
+			err := d.Send(context.Background(), &models.DeadLetter{Subject: "orders", Reason: models.DeadLetterReasonSave})
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Send() error = %v, want error: %v", err, tt.wantErr)
+			}
+			if len(tt.sinks) == 0 && !errors.Is(err, ErrNoSinks) {
+				t.Errorf("Send() error = %v, want ErrNoSinks", err)
+			}
+			for i, sink := range tt.sinks {
+				if sink.err == nil && sink.saved != 1 {
+					t.Errorf("sink %d saved %d dead letters, want 1", i, sink.saved)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+const (
+	DeadLetterReasonUnmarshal  = "unmarshal"
+	DeadLetterReasonValidation = "validation"
+	DeadLetterReasonSave       = "save"
+	DeadLetterReasonConflict   = "conflict"
+	DeadLetterReasonTransition = "transition"
+	DeadLetterReasonNotFound   = "not_found"
+	DeadLetterReasonStale      = "stale"
+)
+
+type DeadLetter struct {
+	Subject    string    `json:"subject"`
+	Sequence   uint64    `json:"sequence"`
+	ReceivedAt time.Time `json:"received_at"`
+	Payload    []byte    `json:"payload"`
+	Reason     string    `json:"reason"`
+	Error      string    `json:"error"`
+	FailedAt   time.Time `json:"failed_at"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "log/slog"
+
 type Delivery struct {
 	Name    string
 	Phone   string
@@ -9,3 +11,16 @@ type Delivery struct {
 	Region  string
 	Email   string
 }
+
+// LogValue groups the fields so log handlers can mask personal data by key.
+func (d Delivery) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("name", d.Name),
+		slog.String("phone", d.Phone),
+		slog.String("zip", d.Zip),
+		slog.String("city", d.City),
+		slog.String("address", d.Address),
+		slog.String("region", d.Region),
+		slog.String("email", d.Email),
+	)
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+type SortOrder string
+
+const (
+	SortCreatedAsc  SortOrder = "date_created"
+	SortCreatedDesc SortOrder = "-date_created"
+)
+
+// OrderCursor points at the last order of the previous page.
+type OrderCursor struct {
+	DateCreated time.Time `json:"date_created"`
+	UID         string    `json:"order_uid"`
+}
+
+type OrderFilter struct {
+	CustomerID      string
+	TrackNumber     string
+	DeliveryService string
+	CreatedFrom     time.Time
+	CreatedTo       time.Time
+	Provider        string
+	Currency        string
+	Sort            SortOrder
+	Limit           int
+	After           *OrderCursor
+}
+
+type OrderPage struct {
+	Orders []Order
+	Next   *OrderCursor
+}
@@ -17,4 +17,6 @@ type Order struct {
 	SmID              int64
 	DateCreated       time.Time
 	OofShard          string
+	Status            Status
+	Version           int64
 }
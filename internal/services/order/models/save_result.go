@@ -0,0 +1,22 @@
+package models
+
+type SaveResult int
+
+const (
+	SaveResultInserted SaveResult = iota + 1
+	SaveResultDuplicate
+	SaveResultConflict
+)
+
+func (r SaveResult) String() string {
+	switch r {
+	case SaveResultInserted:
+		return "inserted"
+	case SaveResultDuplicate:
+		return "duplicate"
+	case SaveResultConflict:
+		return "conflict"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,23 @@
+package models
+
+import "unsafe"
+
+// ApproxSize estimates the memory held by the order, used to bound caches.
+func (o *Order) ApproxSize() int64 {
+	size := int64(unsafe.Sizeof(*o)) +
+		int64(len(o.UID)+len(o.TrackNumber)+len(o.Entry)+len(o.Locale)+len(o.InternalSignature)+
+			len(o.CustomerID)+len(o.DeliveryService)+len(o.Shardkey)+len(o.OofShard))
+
+	d := &o.Delivery
+	size += int64(len(d.Name) + len(d.Phone) + len(d.Zip) + len(d.City) + len(d.Address) + len(d.Region) + len(d.Email))
+
+	p := &o.Payment
+	size += int64(len(p.Transaction) + len(p.RequestID) + len(p.Currency) + len(p.Provider) + len(p.Bank))
+
+	size += int64(cap(o.Items)) * int64(unsafe.Sizeof(Item{}))
+	for _, item := range o.Items {
+		size += int64(len(item.TrackNumber) + len(item.RID) + len(item.Name) + len(item.Size) + len(item.Brand))
+	}
+
+	return size
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+type Status string
+
+const (
+	StatusCreated   Status = "created"
+	StatusPaid      Status = "paid"
+	StatusAssembled Status = "assembled"
+	StatusShipped   Status = "shipped"
+	StatusDelivered Status = "delivered"
+	StatusCancelled Status = "cancelled"
+	StatusReturned  Status = "returned"
+)
+
+// StatusChange is one step of the order timeline. From is empty for the
+// initial created status.
+type StatusChange struct {
+	OrderUID  string
+	From      Status
+	Status    Status
+	ChangedAt time.Time
+	Reason    string
+}
@@ -0,0 +1,92 @@
+package models
+
+// OrderUpdate changes a stored order. Nil fields are left as they are; Items,
+// when not nil, replace all items of the order. Version is the version the
+// producer based the update on.
+type OrderUpdate struct {
+	UID     string
+	Version int64
+
+	TrackNumber       *string
+	Entry             *string
+	Locale            *string
+	InternalSignature *string
+	CustomerID        *string
+	DeliveryService   *string
+	Shardkey          *string
+	SmID              *int64
+	OofShard          *string
+
+	Delivery *DeliveryUpdate
+	Payment  *PaymentUpdate
+	Items    []Item
+}
+
+type DeliveryUpdate struct {
+	Name    *string
+	Phone   *string
+	Zip     *string
+	City    *string
+	Address *string
+	Region  *string
+	Email   *string
+}
+
+type PaymentUpdate struct {
+	Transaction  *string
+	RequestID    *string
+	Currency     *string
+	Provider     *string
+	Amount       *int64
+	PaymentDT    *int64
+	Bank         *string
+	DeliveryCost *int64
+	GoodsTotal   *int64
+	CustomFee    *int64
+}
+
+// Apply copies the set fields of u onto order.
+func (u *OrderUpdate) Apply(order *Order) {
+	set(&order.TrackNumber, u.TrackNumber)
+	set(&order.Entry, u.Entry)
+	set(&order.Locale, u.Locale)
+	set(&order.InternalSignature, u.InternalSignature)
+	set(&order.CustomerID, u.CustomerID)
+	set(&order.DeliveryService, u.DeliveryService)
+	set(&order.Shardkey, u.Shardkey)
+	set(&order.SmID, u.SmID)
+	set(&order.OofShard, u.OofShard)
+
+	if d := u.Delivery; d != nil {
+		set(&order.Delivery.Name, d.Name)
+		set(&order.Delivery.Phone, d.Phone)
+		set(&order.Delivery.Zip, d.Zip)
+		set(&order.Delivery.City, d.City)
+		set(&order.Delivery.Address, d.Address)
+		set(&order.Delivery.Region, d.Region)
+		set(&order.Delivery.Email, d.Email)
+	}
+
+	if p := u.Payment; p != nil {
+		set(&order.Payment.Transaction, p.Transaction)
+		set(&order.Payment.RequestID, p.RequestID)
+		set(&order.Payment.Currency, p.Currency)
+		set(&order.Payment.Provider, p.Provider)
+		set(&order.Payment.Amount, p.Amount)
+		set(&order.Payment.PaymentDT, p.PaymentDT)
+		set(&order.Payment.Bank, p.Bank)
+		set(&order.Payment.DeliveryCost, p.DeliveryCost)
+		set(&order.Payment.GoodsTotal, p.GoodsTotal)
+		set(&order.Payment.CustomFee, p.CustomFee)
+	}
+
+	if u.Items != nil {
+		order.Items = u.Items
+	}
+}
+
+func set[T any](dst *T, src *T) {
+	if src != nil {
+		*dst = *src
+	}
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func storedOrder() Order {
+	return Order{
+		UID:         "b563feb7b2b84b6test",
+		TrackNumber: "WBILMTESTTRACK",
+		Entry:       "WBIL",
+		Delivery: Delivery{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			Zip:     "2639809",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Region:  "Kraiot",
+			Email:   "test@gmail.com",
+		},
+		Payment: Payment{
+			Transaction:  "b563feb7b2b84b6test",
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       1817,
+			PaymentDT:    1637907727,
+			Bank:         "alpha",
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+		},
+		Items: []Item{{
+			ChrtID:      9934930,
+			TrackNumber: "WBILMTESTTRACK",
+			Price:       453,
+			RID:         "ab4219087a764ae0btest",
+			Name:        "Mascaras",
+			Sale:        30,
+			Size:        "0",
+			TotalPrice:  317,
+			NmID:        2389212,
+			Brand:       "Vivienne Sabo",
+			Status:      202,
+		}},
+		Locale:          "en",
+		CustomerID:      "test",
+		DeliveryService: "meest",
+		Shardkey:        "9",
+		SmID:            99,
+		DateCreated:     time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		OofShard:        "1",
+		Status:          StatusCreated,
+		Version:         3,
+	}
+}
+
+func TestOrderUpdateApply(t *testing.T) {
+	str := func(s string) *string { return &s }
+	num := func(n int64) *int64 { return &n }
+
+	tests := []struct {
+		name   string
+		update OrderUpdate
+		want   func(o *Order)
+	}{
+		{
+			name:   "nothing set",
+			update: OrderUpdate{UID: "b563feb7b2b84b6test", Version: 3},
+			want:   func(o *Order) {},
+		},
+		{
+			name: "top-level fields",
+			update: OrderUpdate{
+				TrackNumber: str("NEWTRACK"),
+				Locale:      str("ru"),
+				SmID:        num(0),
+			},
+			want: func(o *Order) {
+				o.TrackNumber, o.Locale, o.SmID = "NEWTRACK", "ru", 0
+			},
+		},
+		{
+			name:   "fields set to empty",
+			update: OrderUpdate{InternalSignature: str(""), OofShard: str("")},
+			want: func(o *Order) {
+				o.InternalSignature, o.OofShard = "", ""
+			},
+		},
+		{
+			name: "part of delivery",
+			update: OrderUpdate{Delivery: &DeliveryUpdate{
+				City:    str("Haifa"),
+				Address: str("Herzl 1"),
+			}},
+			want: func(o *Order) {
+				o.Delivery.City, o.Delivery.Address = "Haifa", "Herzl 1"
+			},
+		},
+		{
+			name:   "empty delivery update",
+			update: OrderUpdate{Delivery: &DeliveryUpdate{}},
+			want:   func(o *Order) {},
+		},
+		{
+			name: "part of payment",
+			update: OrderUpdate{Payment: &PaymentUpdate{
+				Amount:    num(1900),
+				CustomFee: num(83),
+			}},
+			want: func(o *Order) {
+				o.Payment.Amount, o.Payment.CustomFee = 1900, 83
+			},
+		},
+		{
+			name: "items replaced",
+			update: OrderUpdate{Items: []Item{
+				{ChrtID: 1, TrackNumber: "WBILMTESTTRACK", RID: "r1", TotalPrice: 100},
+				{ChrtID: 2, TrackNumber: "WBILMTESTTRACK", RID: "r2", TotalPrice: 217},
+			}},
+			want: func(o *Order) {
+				o.Items = []Item{
+					{ChrtID: 1, TrackNumber: "WBILMTESTTRACK", RID: "r1", TotalPrice: 100},
+					{ChrtID: 2, TrackNumber: "WBILMTESTTRACK", RID: "r2", TotalPrice: 217},
+				}
+			},
+		},
+		{
+			name:   "empty items replace all items",
+			update: OrderUpdate{Items: []Item{}},
+			want:   func(o *Order) { o.Items = []Item{} },
+		},
+		{
+			name:   "identity is not applied",
+			update: OrderUpdate{UID: "other", Version: 7},
+			want:   func(o *Order) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := storedOrder()
+			tt.update.Apply(&got)
+
+			want := storedOrder()
+			tt.want(&want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Apply() =\n%+v\nwant\n%+v", got, want)
+			}
+		})
+	}
+}
@@ -2,38 +2,88 @@ package orderService
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"regexp"
+	"time"
+	"wbnats/internal/lib/logger/sl"
+	"wbnats/internal/lib/metrics"
+	"wbnats/internal/repository"
 	"wbnats/internal/services/order/models"
 )
 
+var (
+	ErrNotFound         = errors.New("order not found")
+	ErrInvalidID        = errors.New("invalid order id")
+	ErrDeadlineExceeded = errors.New("deadline exceeded")
+	ErrUnavailable      = errors.New("order storage is temporarily unavailable")
+	ErrConflict         = errors.New("order with the same uid but different data already exists")
+)
+
+var uidRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]{1,200}$`)
+
+// ValidUID reports whether uid can be stored and looked up again.
+func ValidUID(uid string) bool {
+	return uidRegexp.MatchString(uid)
+}
+
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 500
+)
+
 type Order struct {
-	log         *slog.Logger
-	ordSaver    OrderSaver
-	ordProvider OrderProvider
+	log                *slog.Logger
+	ordSaver           OrderSaver
+	ordProvider        OrderProvider
+	ordLister          OrderLister
+	ordStatusSetter    OrderStatusSetter
+	ordHistoryProvider OrderHistoryProvider
+	ordUpdater         OrderUpdater
 }
 
 type OrderSaver interface {
-	SaveOrder(order *models.Order) (err error)
+	SaveOrder(ctx context.Context, order *models.Order) (models.SaveResult, error)
 }
 
 type OrderProvider interface {
 	Order(ctx context.Context, email string) (models.Order, error)
 }
 
+type OrderLister interface {
+	ListOrders(ctx context.Context, filter models.OrderFilter) (models.OrderPage, error)
+}
+
+type OrderStatusSetter interface {
+	SetOrderStatus(ctx context.Context, change *models.StatusChange, from []models.Status) (models.Status, error)
+}
+
+type OrderHistoryProvider interface {
+	OrderStatusHistory(ctx context.Context, uid string) ([]models.StatusChange, error)
+}
+
 func New(
 	log *slog.Logger,
 	ordSaver OrderSaver,
 	ordProvider OrderProvider,
+	ordLister OrderLister,
+	ordStatusSetter OrderStatusSetter,
+	ordHistoryProvider OrderHistoryProvider,
+	ordUpdater OrderUpdater,
 ) *Order {
 	return &Order{
-		log:         log,
-		ordSaver:    ordSaver,
-		ordProvider: ordProvider,
+		log:                log,
+		ordSaver:           ordSaver,
+		ordProvider:        ordProvider,
+		ordLister:          ordLister,
+		ordStatusSetter:    ordStatusSetter,
+		ordHistoryProvider: ordHistoryProvider,
+		ordUpdater:         ordUpdater,
 	}
 }
 
-func (o *Order) NewOrder(order *models.Order) error {
+func (o *Order) NewOrder(ctx context.Context, order *models.Order) (models.SaveResult, error) {
 	const op = "Order.NewOrder"
 
 	log := o.log.With(
@@ -43,11 +93,29 @@ func (o *Order) NewOrder(order *models.Order) error {
 
 	log.Info("processing a new order")
 
-	err := o.ordSaver.SaveOrder(order)
+	if order.Status == "" {
+		order.Status = models.StatusCreated
+	}
+	order.Version = 1
+
+	start := time.Now()
+	result, err := o.ordSaver.SaveOrder(ctx, order)
+	metrics.StorageDuration.WithLabelValues("save_order", metrics.Status(err)).Observe(time.Since(start).Seconds())
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		if errors.Is(err, repository.ErrUnavailable) {
+			return result, fmt.Errorf("%s: %w: %w", op, ErrUnavailable, err)
+		}
+		if errors.Is(err, repository.ErrConflict) {
+			log.Warn("order conflicts with the stored one", sl.Err(err))
+			return result, fmt.Errorf("%s: %w: %w", op, ErrConflict, err)
+		}
+		return result, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if result == models.SaveResultDuplicate {
+		log.Info("order is already stored, skipping duplicate")
 	}
-	return nil
+	return result, nil
 }
 
 func (o *Order) Order(ctx context.Context, uid string) (models.Order, error) {
@@ -58,10 +126,58 @@ func (o *Order) Order(ctx context.Context, uid string) (models.Order, error) {
 		slog.String("orderUID", uid),
 	)
 
+	if !uidRegexp.MatchString(uid) {
+		return models.Order{}, fmt.Errorf("%s: %w", op, ErrInvalidID)
+	}
+
 	log.Info("getting order information")
+	start := time.Now()
 	order, err := o.ordProvider.Order(ctx, uid)
+	metrics.StorageDuration.WithLabelValues("order", metrics.Status(err)).Observe(time.Since(start).Seconds())
 	if err != nil {
-		return models.Order{}, fmt.Errorf("%s: %w", op, err)
+		return models.Order{}, fmt.Errorf("%s: %w", op, translateErr(err))
 	}
 	return order, nil
 }
+
+func (o *Order) Orders(ctx context.Context, filter models.OrderFilter) (models.OrderPage, error) {
+	const op = "Order.Orders"
+
+	log := o.log.With(
+		slog.String("op", op),
+	)
+
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultListLimit
+	}
+	if filter.Limit > MaxListLimit {
+		filter.Limit = MaxListLimit
+	}
+	if filter.Sort == "" {
+		filter.Sort = models.SortCreatedDesc
+	}
+
+	log.Info("listing orders", slog.Int("limit", filter.Limit), slog.String("sort", string(filter.Sort)))
+	start := time.Now()
+	page, err := o.ordLister.ListOrders(ctx, filter)
+	metrics.StorageDuration.WithLabelValues("list_orders", metrics.Status(err)).Observe(time.Since(start).Seconds())
+	if err != nil {
+		return models.OrderPage{}, fmt.Errorf("%s: %w", op, translateErr(err))
+	}
+	return page, nil
+}
+
+// translateErr maps storage and context errors to the service error set while
+// keeping the original cause in the chain.
+func translateErr(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("%w: %w", ErrDeadlineExceeded, err)
+	case errors.Is(err, repository.ErrUnavailable):
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,33 @@
+package orderService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"wbnats/internal/repository"
+	"wbnats/internal/services/order/models"
+)
+
+type fakeSaver struct {
+	result models.SaveResult
+	err    error
+}
+
+func (f *fakeSaver) SaveOrder(context.Context, *models.Order) (models.SaveResult, error) {
+	return f.result, f.err
+}
+
+func TestNewOrderConflictKeepsCause(t *testing.T) {
+	cause := fmt.Errorf("Storage.SaveOrder: order b563feb7b2b84b6test: %w", repository.ErrConflict)
+	o := New(discard, &fakeSaver{result: models.SaveResultConflict, err: cause}, nil, nil, nil, nil, nil)
+
+	_, err := o.NewOrder(context.Background(), &models.Order{UID: "b563feb7b2b84b6test"})
+
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("NewOrder() error = %v, want ErrConflict", err)
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("NewOrder() error = %v, want it to wrap %v", err, cause)
+	}
+}
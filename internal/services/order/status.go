@@ -0,0 +1,103 @@
+package orderService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"wbnats/internal/lib/metrics"
+	"wbnats/internal/repository"
+	"wbnats/internal/services/order/models"
+)
+
+var (
+	ErrInvalidStatus     = errors.New("unknown order status")
+	ErrInvalidTransition = errors.New("status transition is not allowed")
+)
+
+// transitions lists the statuses an order may move to from each status.
+// Cancelled and returned are final.
+var transitions = map[models.Status][]models.Status{
+	models.StatusCreated:   {models.StatusPaid, models.StatusCancelled},
+	models.StatusPaid:      {models.StatusAssembled, models.StatusCancelled},
+	models.StatusAssembled: {models.StatusShipped, models.StatusCancelled},
+	models.StatusShipped:   {models.StatusDelivered, models.StatusReturned},
+	models.StatusDelivered: {models.StatusReturned},
+	models.StatusCancelled: {},
+	models.StatusReturned:  {},
+}
+
+// sourcesOf returns the statuses from which an order may move to status.
+func sourcesOf(status models.Status) []models.Status {
+	var sources []models.Status
+	for from, targets := range transitions {
+		for _, to := range targets {
+			if to == status {
+				sources = append(sources, from)
+			}
+		}
+	}
+	return sources
+}
+
+// ChangeStatus applies a status change event. Repeating the current status is
+// reported as a duplicate, a step not allowed by the transitions table as
+// ErrInvalidTransition.
+func (o *Order) ChangeStatus(ctx context.Context, change *models.StatusChange) (models.SaveResult, error) {
+	const op = "Order.ChangeStatus"
+
+	log := o.log.With(
+		slog.String("op", op),
+		slog.String("orderUID", change.OrderUID),
+		slog.String("status", string(change.Status)),
+	)
+
+	if !uidRegexp.MatchString(change.OrderUID) {
+		return 0, fmt.Errorf("%s: %w", op, ErrInvalidID)
+	}
+	if _, ok := transitions[change.Status]; !ok {
+		return 0, fmt.Errorf("%s: %w: %q", op, ErrInvalidStatus, change.Status)
+	}
+
+	log.Info("changing order status")
+
+	start := time.Now()
+	current, err := o.ordStatusSetter.SetOrderStatus(ctx, change, sourcesOf(change.Status))
+	metrics.StorageDuration.WithLabelValues("set_order_status", metrics.Status(err)).Observe(time.Since(start).Seconds())
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			if current == change.Status {
+				log.Info("order already has this status, skipping duplicate")
+				return models.SaveResultDuplicate, nil
+			}
+			return models.SaveResultConflict, fmt.Errorf("%s: %w: %s -> %s", op, ErrInvalidTransition, current, change.Status)
+		}
+		return 0, fmt.Errorf("%s: %w", op, translateErr(err))
+	}
+
+	log.Info("order status changed", slog.String("from", string(current)))
+	return models.SaveResultInserted, nil
+}
+
+func (o *Order) History(ctx context.Context, uid string) ([]models.StatusChange, error) {
+	const op = "Order.History"
+
+	log := o.log.With(
+		slog.String("op", op),
+		slog.String("orderUID", uid),
+	)
+
+	if !uidRegexp.MatchString(uid) {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidID)
+	}
+
+	log.Info("getting order status history")
+	start := time.Now()
+	history, err := o.ordHistoryProvider.OrderStatusHistory(ctx, uid)
+	metrics.StorageDuration.WithLabelValues("order_status_history", metrics.Status(err)).Observe(time.Since(start).Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, translateErr(err))
+	}
+	return history, nil
+}
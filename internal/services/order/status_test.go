@@ -0,0 +1,232 @@
+package orderService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+	"wbnats/internal/repository"
+	"wbnats/internal/services/order/models"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// fakeStatusStore behaves like the repository: it moves an order only if its
+// current status is one of from.
+type fakeStatusStore struct {
+	statuses map[string]models.Status
+	err      error
+}
+
+func (f *fakeStatusStore) SetOrderStatus(_ context.Context, change *models.StatusChange, from []models.Status) (models.Status, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	current, ok := f.statuses[change.OrderUID]
+	if !ok {
+		return "", repository.ErrNotFound
+	}
+	if !slices.Contains(from, current) {
+		return current, repository.ErrConflict
+	}
+	f.statuses[change.OrderUID] = change.Status
+	return current, nil
+}
+
+var allStatuses = []models.Status{
+	models.StatusCreated,
+	models.StatusPaid,
+	models.StatusAssembled,
+	models.StatusShipped,
+	models.StatusDelivered,
+	models.StatusCancelled,
+	models.StatusReturned,
+}
+
+func TestChangeStatusTransitions(t *testing.T) {
+	// allowed is spelled out rather than derived from transitions so a change
+	// of the table shows up here.
+	allowed := map[[2]models.Status]bool{
+		{models.StatusCreated, models.StatusPaid}:        true,
+		{models.StatusCreated, models.StatusCancelled}:   true,
+		{models.StatusPaid, models.StatusAssembled}:      true,
+		{models.StatusPaid, models.StatusCancelled}:      true,
+		{models.StatusAssembled, models.StatusShipped}:   true,
+		{models.StatusAssembled, models.StatusCancelled}: true,
+		{models.StatusShipped, models.StatusDelivered}:   true,
+		{models.StatusShipped, models.StatusReturned}:    true,
+		{models.StatusDelivered, models.StatusReturned}:  true,
+	}
+
+	for _, from := range allStatuses {
+		for _, to := range allStatuses {
+			t.Run(fmt.Sprintf("%s to %s", from, to), func(t *testing.T) {
+				store := &fakeStatusStore{statuses: map[string]models.Status{"order": from}}
+				o := New(discard, nil, nil, nil, store, nil, nil)
+
+				result, err := o.ChangeStatus(context.Background(), &models.StatusChange{
+					OrderUID:  "order",
+					Status:    to,
+					ChangedAt: time.Now(),
+				})
+
+				switch {
+				case from == to:
+					if err != nil || result != models.SaveResultDuplicate {
+						t.Errorf("ChangeStatus() = %v, %v, want duplicate", result, err)
+					}
+				case allowed[[2]models.Status{from, to}]:
+					if err != nil || result != models.SaveResultInserted {
+						t.Errorf("ChangeStatus() = %v, %v, want inserted", result, err)
+					}
+					if got := store.statuses["order"]; got != to {
+						t.Errorf("stored status = %s, want %s", got, to)
+					}
+				default:
+					if !errors.Is(err, ErrInvalidTransition) || result != models.SaveResultConflict {
+						t.Errorf("ChangeStatus() = %v, %v, want ErrInvalidTransition", result, err)
+					}
+					if got := store.statuses["order"]; got != from {
+						t.Errorf("stored status = %s, want it left at %s", got, from)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestChangeStatusSequences(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []models.Status
+		// failAt is the index of the first rejected step, -1 if all apply.
+		failAt int
+	}{
+		{
+			name:   "delivered and returned",
+			steps:  []models.Status{models.StatusPaid, models.StatusAssembled, models.StatusShipped, models.StatusDelivered, models.StatusReturned},
+			failAt: -1,
+		},
+		{
+			name:   "cancelled before shipping",
+			steps:  []models.Status{models.StatusPaid, models.StatusAssembled, models.StatusCancelled},
+			failAt: -1,
+		},
+		{
+			name:   "repeated status",
+			steps:  []models.Status{models.StatusPaid, models.StatusPaid, models.StatusAssembled},
+			failAt: -1,
+		},
+		{
+			name:   "shipped before paid",
+			steps:  []models.Status{models.StatusShipped},
+			failAt: 0,
+		},
+		{
+			name:   "paid arriving after assembled",
+			steps:  []models.Status{models.StatusPaid, models.StatusAssembled, models.StatusPaid},
+			failAt: 2,
+		},
+		{
+			name:   "cancelled after shipping",
+			steps:  []models.Status{models.StatusPaid, models.StatusAssembled, models.StatusShipped, models.StatusCancelled},
+			failAt: 3,
+		},
+		{
+			name:   "nothing after cancelled",
+			steps:  []models.Status{models.StatusCancelled, models.StatusPaid},
+			failAt: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStatusStore{statuses: map[string]models.Status{"order": models.StatusCreated}}
+			o := New(discard, nil, nil, nil, store, nil, nil)
+
+			for i, status := range tt.steps {
+				_, err := o.ChangeStatus(context.Background(), &models.StatusChange{OrderUID: "order", Status: status})
+				if i == tt.failAt {
+					if !errors.Is(err, ErrInvalidTransition) {
+						t.Fatalf("step %d (%s): error = %v, want ErrInvalidTransition", i, status, err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("step %d (%s): error = %v", i, status, err)
+				}
+			}
+			if tt.failAt >= 0 {
+				t.Fatalf("step %d was not rejected", tt.failAt)
+			}
+		})
+	}
+}
+
+func TestChangeStatusErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		change models.StatusChange
+		store  *fakeStatusStore
+		want   error
+	}{
+		{
+			name:   "unknown status",
+			change: models.StatusChange{OrderUID: "order", Status: "lost"},
+			store:  &fakeStatusStore{statuses: map[string]models.Status{"order": models.StatusCreated}},
+			want:   ErrInvalidStatus,
+		},
+		{
+			name:   "malformed uid",
+			change: models.StatusChange{OrderUID: "order 1", Status: models.StatusPaid},
+			store:  &fakeStatusStore{},
+			want:   ErrInvalidID,
+		},
+		{
+			name:   "unknown order",
+			change: models.StatusChange{OrderUID: "order", Status: models.StatusPaid},
+			store:  &fakeStatusStore{statuses: map[string]models.Status{}},
+			want:   ErrNotFound,
+		},
+		{
+			name:   "storage unavailable",
+			change: models.StatusChange{OrderUID: "order", Status: models.StatusPaid},
+			store:  &fakeStatusStore{err: fmt.Errorf("connect: %w", repository.ErrUnavailable)},
+			want:   ErrUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := New(discard, nil, nil, nil, tt.store, nil, nil)
+
+			if _, err := o.ChangeStatus(context.Background(), &tt.change); !errors.Is(err, tt.want) {
+				t.Errorf("ChangeStatus() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourcesOf(t *testing.T) {
+	tests := []struct {
+		status models.Status
+		want   []models.Status
+	}{
+		{models.StatusCreated, nil},
+		{models.StatusPaid, []models.Status{models.StatusCreated}},
+		{models.StatusCancelled, []models.Status{models.StatusAssembled, models.StatusCreated, models.StatusPaid}},
+		{models.StatusReturned, []models.Status{models.StatusDelivered, models.StatusShipped}},
+	}
+
+	for _, tt := range tests {
+		got := sourcesOf(tt.status)
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("sourcesOf(%s) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
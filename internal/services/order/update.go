@@ -0,0 +1,90 @@
+package orderService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"wbnats/internal/lib/logger/sl"
+	"wbnats/internal/lib/metrics"
+	"wbnats/internal/repository"
+	"wbnats/internal/services/order/models"
+)
+
+var (
+	ErrStaleVersion  = errors.New("order version does not match the update")
+	ErrInvalidUpdate = errors.New("update leaves the order inconsistent")
+)
+
+type OrderUpdater interface {
+	UpdateOrder(ctx context.Context, uid string, version int64, apply func(order *models.Order) error) (models.Order, error)
+}
+
+// UpdateOrder applies a partial or full update to the stored order if it is
+// still at update.Version, and returns the order as stored afterwards. Updates
+// based on another version are rejected with ErrStaleVersion.
+func (o *Order) UpdateOrder(ctx context.Context, update *models.OrderUpdate) (models.Order, error) {
+	const op = "Order.UpdateOrder"
+
+	log := o.log.With(
+		slog.String("op", op),
+		slog.String("orderUID", update.UID),
+		slog.Int64("version", update.Version),
+	)
+
+	if !uidRegexp.MatchString(update.UID) {
+		return models.Order{}, fmt.Errorf("%s: %w", op, ErrInvalidID)
+	}
+
+	log.Info("updating order")
+
+	start := time.Now()
+	order, err := o.ordUpdater.UpdateOrder(ctx, update.UID, update.Version, func(order *models.Order) error {
+		update.Apply(order)
+		return checkConsistency(order)
+	})
+	metrics.StorageDuration.WithLabelValues("update_order", metrics.Status(err)).Observe(time.Since(start).Seconds())
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrConflict):
+			log.Warn("rejecting stale order update", sl.Err(err))
+			return models.Order{}, fmt.Errorf("%s: %w: %w", op, ErrStaleVersion, err)
+		case errors.Is(err, ErrInvalidUpdate):
+			return models.Order{}, fmt.Errorf("%s: %w", op, err)
+		default:
+			return models.Order{}, fmt.Errorf("%s: %w", op, translateErr(err))
+		}
+	}
+
+	log.Info("order updated", slog.Int64("new_version", order.Version))
+	return order, nil
+}
+
+// checkConsistency repeats the cross-field checks producers' orders pass on
+// arrival, since an update may change one side of them only.
+func checkConsistency(order *models.Order) error {
+	if len(order.Items) == 0 {
+		return fmt.Errorf("%w: order must contain at least one item", ErrInvalidUpdate)
+	}
+
+	var goodsTotal int64
+	for i, item := range order.Items {
+		if item.TrackNumber != order.TrackNumber {
+			return fmt.Errorf("%w: items[%d].track_number %q differs from order track_number %q",
+				ErrInvalidUpdate, i, item.TrackNumber, order.TrackNumber)
+		}
+		goodsTotal += item.TotalPrice
+	}
+
+	p := &order.Payment
+	if goodsTotal != p.GoodsTotal {
+		return fmt.Errorf("%w: payment.goods_total %d differs from the sum of items total_price %d",
+			ErrInvalidUpdate, p.GoodsTotal, goodsTotal)
+	}
+	if expected := p.GoodsTotal + p.DeliveryCost + p.CustomFee; p.Amount != expected {
+		return fmt.Errorf("%w: payment.amount %d differs from goods_total + delivery_cost + custom_fee %d",
+			ErrInvalidUpdate, p.Amount, expected)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package orderService
+
+import (
+	"errors"
+	"testing"
+	"wbnats/internal/services/order/models"
+)
+
+func consistentOrder() *models.Order {
+	return &models.Order{
+		UID:         "b563feb7b2b84b6test",
+		TrackNumber: "WBILMTESTTRACK",
+		Payment: models.Payment{
+			Amount:       1900,
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+			CustomFee:    83,
+		},
+		Items: []models.Item{
+			{ChrtID: 1, TrackNumber: "WBILMTESTTRACK", RID: "r1", TotalPrice: 100},
+			{ChrtID: 2, TrackNumber: "WBILMTESTTRACK", RID: "r2", TotalPrice: 217},
+		},
+		Version: 3,
+	}
+}
+
+func TestCheckConsistency(t *testing.T) {
+	str := func(s string) *string { return &s }
+	num := func(n int64) *int64 { return &n }
+
+	tests := []struct {
+		name    string
+		update  models.OrderUpdate
+		wantErr bool
+	}{
+		{
+			name:   "unchanged order",
+			update: models.OrderUpdate{},
+		},
+		{
+			name:   "fields outside the checks",
+			update: models.OrderUpdate{Locale: str("ru"), Delivery: &models.DeliveryUpdate{City: str("Haifa")}},
+		},
+		{
+			name:    "no items",
+			update:  models.OrderUpdate{Items: []models.Item{}},
+			wantErr: true,
+		},
+		{
+			name:    "track number changed without items",
+			update:  models.OrderUpdate{TrackNumber: str("NEWTRACK")},
+			wantErr: true,
+		},
+		{
+			name: "track number changed with items",
+			update: models.OrderUpdate{
+				TrackNumber: str("NEWTRACK"),
+				Items:       []models.Item{{ChrtID: 1, TrackNumber: "NEWTRACK", RID: "r1", TotalPrice: 317}},
+			},
+		},
+		{
+			name: "item with another track number",
+			update: models.OrderUpdate{Items: []models.Item{
+				{ChrtID: 1, TrackNumber: "WBILMTESTTRACK", RID: "r1", TotalPrice: 100},
+				{ChrtID: 2, TrackNumber: "OTHER", RID: "r2", TotalPrice: 217},
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "items replaced without goods total",
+			update:  models.OrderUpdate{Items: []models.Item{{ChrtID: 1, TrackNumber: "WBILMTESTTRACK", RID: "r1", TotalPrice: 100}}},
+			wantErr: true,
+		},
+		{
+			name: "items replaced with totals",
+			update: models.OrderUpdate{
+				Items:   []models.Item{{ChrtID: 1, TrackNumber: "WBILMTESTTRACK", RID: "r1", TotalPrice: 100}},
+				Payment: &models.PaymentUpdate{GoodsTotal: num(100), Amount: num(1683)},
+			},
+		},
+		{
+			name:    "goods total without amount",
+			update:  models.OrderUpdate{Payment: &models.PaymentUpdate{GoodsTotal: num(300)}},
+			wantErr: true,
+		},
+		{
+			name:    "delivery cost without amount",
+			update:  models.OrderUpdate{Payment: &models.PaymentUpdate{DeliveryCost: num(1000)}},
+			wantErr: true,
+		},
+		{
+			name:   "delivery cost with amount",
+			update: models.OrderUpdate{Payment: &models.PaymentUpdate{DeliveryCost: num(1000), Amount: num(1400)}},
+		},
+		{
+			name:    "custom fee removed without amount",
+			update:  models.OrderUpdate{Payment: &models.PaymentUpdate{CustomFee: num(0)}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := consistentOrder()
+			tt.update.Apply(order)
+
+			err := checkConsistency(order)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("checkConsistency() error = %v, want error: %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidUpdate) {
+				t.Errorf("checkConsistency() error = %v, want ErrInvalidUpdate", err)
+			}
+		})
+	}
+}